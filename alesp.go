@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	// alespURL is the open data repository of the Assembleia Legislativa de São Paulo.
+	alespURL = "https://www.al.sp.gov.br/repositorioDados/deputados"
+
+	// alespExpensesFile reads the office expenses dump from disk instead of downloading it.
+	alespExpensesFile string
+)
+
+type alespDeputy struct {
+	ID             string `xml:"Matricula"`
+	Name           string `xml:"NomeParlamentar"`
+	PoliticalParty string `xml:"Partido"`
+}
+
+type alespExpense struct {
+	ID           string `xml:"Matricula"`
+	Year         int    `xml:"Ano"`
+	Month        int    `xml:"Mes"`
+	Type         string `xml:"Tipo"`
+	SupplierName string `xml:"Fornecedor"`
+	SupplierCNPJ string `xml:"CNPJ"`
+	Value        string `xml:"Valor"`
+}
+
+// alespSource reads the deputies of ALESP and their office expenses (verba de gabinete),
+// stored as the parliamentary quota. The dump covers every year and is read once per run.
+type alespSource struct {
+	once     sync.Once
+	name     string
+	expenses map[string][]alespExpense
+	err      error
+}
+
+func init() {
+	RegisterSource("alesp", func() Source { return &alespSource{} })
+}
+
+func runALESP(ctx context.Context) error {
+	return runSource(ctx, &alespSource{}, "alesp")
+}
+
+func (s *alespSource) ListMembers(ctx context.Context) ([]*Deputy, error) {
+	data, err := readDump(ctx, alespURL+"/deputados.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Deputies []alespDeputy `xml:"Deputado"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error.alesp.deputies: %v", err)
+	}
+
+	var deputies []*Deputy
+	for _, d := range list.Deputies {
+		deputies = append(deputies, &Deputy{
+			ID:             strings.TrimSpace(d.ID),
+			Name:           strings.TrimSpace(d.Name),
+			PoliticalParty: strings.TrimSpace(d.PoliticalParty),
+			State:          "SP",
+		})
+	}
+
+	return deputies, nil
+}
+
+func (s *alespSource) FetchDetails(ctx context.Context, member *Deputy) error {
+	s.once.Do(func() {
+		s.name = alespExpensesFile
+		if s.name == "" {
+			s.name = alespURL + "/despesas_gabinetes.xml"
+		}
+
+		var data []byte
+		if data, s.err = readDump(ctx, s.name); s.err == nil {
+			s.expenses, s.err = parseALESPExpenses(bytes.NewReader(data))
+		}
+	})
+	if s.err != nil {
+		return s.err
+	}
+
+	member.SourceURL = s.name
+	member.ParliamentaryQuota, member.ParliamentaryQuotaDetails, member.AirTickets = 0, nil, 0
+	member.MissingFields = append([]string(nil), portalOnlyFields...)
+
+	for _, e := range s.expenses[member.ID] {
+		if e.Year != year || len(months) > 0 && !containsMonth(months, e.Month) {
+			continue
+		}
+
+		value, err := parseCEAPValue(strings.TrimSpace(e.Value))
+		if err != nil {
+			warnf("skipping expense of deputy %s: %v", member.ID, err)
+			continue
+		}
+
+		addDeputyFigures(member, quotaExpense(strings.TrimSpace(e.Type), value, CostDetail{
+			SupplierName: strings.TrimSpace(e.SupplierName),
+			SupplierCNPJ: strings.TrimSpace(e.SupplierCNPJ),
+		}))
+	}
+
+	return nil
+}
+
+// parseALESPExpenses streams the despesa elements of the dump, grouped by registration number.
+func parseALESPExpenses(r io.Reader) (map[string][]alespExpense, error) {
+	expenses := map[string][]alespExpense{}
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error.alesp.expenses: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "despesa" {
+			continue
+		}
+
+		var e alespExpense
+		if err := decoder.DecodeElement(&e, &start); err != nil {
+			return nil, fmt.Errorf("error.alesp.expenses: %v", err)
+		}
+		e.ID = strings.TrimSpace(e.ID)
+		expenses[e.ID] = append(expenses[e.ID], e)
+	}
+
+	return expenses, nil
+}
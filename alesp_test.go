@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestALESPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/deputados.xml":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Deputados>
+ <Deputado><IdDeputado>1</IdDeputado><Matricula>300001</Matricula><NomeParlamentar>Fulana</NomeParlamentar><Partido>PT</Partido></Deputado>
+</Deputados>`)
+		case "/despesas_gabinetes.xml":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<despesas>
+ <despesa><Deputado>Fulana</Deputado><Matricula>300001</Matricula><Ano>2024</Ano><Mes>1</Mes><Tipo>COMBUSTÍVEIS</Tipo><CNPJ>123</CNPJ><Fornecedor>POSTO</Fornecedor><Valor>100.50</Valor></despesa>
+ <despesa><Deputado>Fulana</Deputado><Matricula>300001</Matricula><Ano>2024</Ano><Mes>2</Mes><Tipo>COMBUSTÍVEIS</Tipo><CNPJ>123</CNPJ><Fornecedor>POSTO</Fornecedor><Valor>49.50</Valor></despesa>
+ <despesa><Deputado>Fulana</Deputado><Matricula>300001</Matricula><Ano>2023</Ano><Mes>2</Mes><Tipo>COMBUSTÍVEIS</Tipo><CNPJ>123</CNPJ><Fornecedor>POSTO</Fornecedor><Valor>1000</Valor></despesa>
+</despesas>`)
+		}
+	}))
+	defer server.Close()
+
+	baseURL, baseYear := alespURL, year
+	alespURL, year = server.URL, 2024
+	defer func() {
+		alespURL, year = baseURL, baseYear
+	}()
+
+	source := &alespSource{}
+	members, err := source.ListMembers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, &Deputy{ID: "300001", Name: "Fulana", PoliticalParty: "PT", State: "SP"}, members[0])
+
+	require.NoError(t, source.FetchDetails(context.Background(), members[0]))
+	assert.Equal(t, 150.0, members[0].ParliamentaryQuota)
+	assert.Equal(t, portalOnlyFields, members[0].MissingFields)
+}
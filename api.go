@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+var (
+	apiURL = "https://dadosabertos.camara.leg.br/api/v2"
+)
+
+type apiLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+type apiDeputy struct {
+	ID             int    `json:"id"`
+	Name           string `json:"nome"`
+	PoliticalParty string `json:"siglaPartido"`
+	State          string `json:"siglaUf"`
+}
+
+type apiExpense struct {
+	Year           int     `json:"ano"`
+	Month          int     `json:"mes"`
+	Type           string  `json:"tipoDespesa"`
+	NetValue       float64 `json:"valorLiquido"`
+	SupplierName   string  `json:"nomeFornecedor"`
+	SupplierCNPJ   string  `json:"cnpjCpfFornecedor"`
+	DocumentDate   string  `json:"dataDocumento"`
+	DocumentNumber string  `json:"numDocumento"`
+	DocumentURL    string  `json:"urlDocumento"`
+}
+
+// getAPIPages requests url and every page linked from it as next, decoding the
+// dados array of each page with page.
+func getAPIPages(ctx context.Context, url string, page func(data json.RawMessage) error) error {
+	client := &contextClient{ctx: ctx, client: httpClient}
+
+	for url != "" {
+		debugf("visit %s", url)
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		var body struct {
+			Data  json.RawMessage `json:"dados"`
+			Links []apiLink       `json:"links"`
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("error.api.status: %s %s", url, resp.Status)
+			}
+			return json.NewDecoder(resp.Body).Decode(&body)
+		}()
+		if err != nil {
+			return err
+		}
+
+		if err := page(body.Data); err != nil {
+			return fmt.Errorf("error.api.decode: %s: %v", url, err)
+		}
+
+		url = ""
+		for _, link := range body.Links {
+			if link.Rel == "next" {
+				url = link.Href
+			}
+		}
+	}
+
+	return nil
+}
+
+// listAPIDeputies lists the deputies of the legislature, asking for each state of -uf when it is set.
+func listAPIDeputies(ctx context.Context) ([]*Deputy, error) {
+	ufs := ufFilter
+	if len(ufs) == 0 {
+		ufs = []string{""}
+	}
+
+	var lists [][]*Deputy
+	for _, uf := range ufs {
+		query := url.Values{}
+		query.Set("idLegislatura", strconv.Itoa(legislatury))
+		query.Set("siglaUf", uf)
+		query.Set("ordem", "ASC")
+		query.Set("ordenarPor", "nome")
+		query.Set("itens", "100")
+
+		var deputies []*Deputy
+		err := getAPIPages(ctx, apiURL+"/deputados?"+query.Encode(), func(data json.RawMessage) error {
+			var page []apiDeputy
+			if err := json.Unmarshal(data, &page); err != nil {
+				return err
+			}
+
+			for _, d := range page {
+				deputies = append(deputies, &Deputy{
+					ID:             strconv.Itoa(d.ID),
+					Name:           d.Name,
+					PoliticalParty: d.PoliticalParty,
+					State:          d.State,
+				})
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, deputies)
+	}
+
+	return dedupeDeputies(lists...), nil
+}
+
+func apiExpensesURL(id string, month int) string {
+	query := url.Values{}
+	query.Set("ano", strconv.Itoa(year))
+	if month > 0 {
+		query.Set("mes", strconv.Itoa(month))
+	}
+	query.Set("itens", "100")
+
+	return fmt.Sprintf("%s/deputados/%s/despesas?%s", apiURL, id, query.Encode())
+}
+
+// visitAPIDeputyDetails fills the parliamentary quota of deputy from its expenses in the API.
+// The figures the API does not publish are marked missing.
+func visitAPIDeputyDetails(ctx context.Context, deputy *Deputy, month int) error {
+	url := apiExpensesURL(deputy.ID, month)
+	deputy.SourceURL = url
+	deputy.ParliamentaryQuota, deputy.AirTickets = 0, 0
+	deputy.ParliamentaryQuotaDetails = nil
+	deputy.MissingFields = nil
+
+	fetched := &Deputy{}
+	err := getAPIPages(ctx, url, func(data json.RawMessage) error {
+		var page []apiExpense
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+
+		for _, e := range page {
+			addDeputyFigures(fetched, quotaExpense(e.Type, e.NetValue, CostDetail{
+				Date:           e.DocumentDate,
+				SupplierName:   e.SupplierName,
+				SupplierCNPJ:   e.SupplierCNPJ,
+				DocumentNumber: e.DocumentNumber,
+				DocumentURL:    e.DocumentURL,
+			}))
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%w: deputy %s: %v", errRequestTimeout, deputy.ID, err)
+		}
+		return err
+	}
+
+	deputy.ParliamentaryQuota = fetched.ParliamentaryQuota
+	deputy.ParliamentaryQuotaDetails = fetched.ParliamentaryQuotaDetails
+	deputy.AirTickets = fetched.AirTickets
+	for _, field := range portalOnlyFields {
+		markMissing(deputy, field)
+	}
+
+	return nil
+}
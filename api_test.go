@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisitAPIDeputyDetails(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("pagina") {
+		case "":
+			fmt.Fprintf(w, `{"dados": [
+				{"ano": 2024, "mes": 1, "tipoDespesa": "COMBUSTÍVEIS E LUBRIFICANTES.", "valorLiquido": 100.5},
+				{"ano": 2024, "mes": 1, "tipoDespesa": "TELEFONIA", "valorLiquido": 20}
+			], "links": [{"rel": "next", "href": "%s%s&pagina=2"}]}`, server.URL, r.URL.String())
+		default:
+			fmt.Fprint(w, `{"dados": [
+				{"ano": 2024, "mes": 2, "tipoDespesa": "COMBUSTÍVEIS E LUBRIFICANTES.", "valorLiquido": 50}
+			], "links": []}`)
+		}
+	}))
+	defer server.Close()
+
+	apiURL = server.URL
+	defer func() {
+		apiURL = "https://dadosabertos.camara.leg.br/api/v2"
+	}()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, visitAPIDeputyDetails(context.Background(), deputy, 0))
+
+	assert.Equal(t, server.URL+"/deputados/204554/despesas?ano=2024&itens=100", deputy.SourceURL)
+	assert.Equal(t, 170.5, deputy.ParliamentaryQuota)
+	assert.Equal(t, []CostDetail{
+		{Description: "COMBUSTÍVEIS E LUBRIFICANTES.", Value: 150.5},
+		{Description: "TELEFONIA", Value: 20},
+	}, deputy.ParliamentaryQuotaDetails)
+	assert.Equal(t, portalOnlyFields, deputy.MissingFields)
+}
+
+func TestVisitHybridDeputyDetails(t *testing.T) {
+	page, err := os.ReadFile("testdata/deputy.html")
+	require.NoError(t, err)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"dados": [{"ano": 2024, "mes": 1, "tipoDespesa": "TELEFONIA", "valorLiquido": 20}], "links": []}`)
+	}))
+	defer api.Close()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	}))
+	defer site.Close()
+
+	apiURL, baseURL, sources = api.URL, site.URL, []string{"api", "html"}
+	defer func() {
+		apiURL = "https://dadosabertos.camara.leg.br/api/v2"
+		baseURL = "https://www.camara.leg.br"
+		sources = []string{"html"}
+	}()
+
+	deputy := &Deputy{ID: "1"}
+	require.NoError(t, visitDeputyDetails(context.Background(), deputy, 0))
+
+	assert.Equal(t, 20.0, deputy.ParliamentaryQuota)
+	assert.Equal(t, 41650.92, deputy.Salary)
+	assert.Equal(t, 1338571.75, deputy.OfficeBudget)
+	assert.Empty(t, deputy.MissingFields)
+	assert.Equal(t, map[string]string{
+		"salary":                    "html",
+		"officeBudget":              "html",
+		"parliamentaryQuota":        "api",
+		"parliamentaryQuotaDetails": "api",
+		"airTickets":                "api",
+		"travelExpenses":            "html",
+		"housingAllowance":          "html",
+	}, deputy.FieldSources)
+}
+
+func TestFetchDeputyProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/deputados/204554", r.URL.Path)
+		fmt.Fprint(w, `{"dados": {
+			"id": 204554,
+			"nomeCivil": "ABILIO JACQUES BRUNINI MOUMER",
+			"dataNascimento": "1984-05-09",
+			"sexo": "M",
+			"escolaridade": "Superior",
+			"ufNascimento": "MT",
+			"municipioNascimento": "Cuiabá",
+			"ultimoStatus": {
+				"urlFoto": "https://www.camara.leg.br/internet/deputado/bandep/204554.jpg",
+				"email": "",
+				"gabinete": {"nome": "614", "predio": "4", "telefone": "3215-5614", "email": "dep.abiliobrunini@camara.leg.br"}
+			}
+		}, "links": []}`)
+	}))
+	defer server.Close()
+
+	apiURL = server.URL
+	defer func() {
+		apiURL = "https://dadosabertos.camara.leg.br/api/v2"
+	}()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, fetchDeputyProfile(context.Background(), deputy))
+
+	assert.Equal(t, &DeputyProfile{
+		CivilName:  "ABILIO JACQUES BRUNINI MOUMER",
+		BirthDate:  "1984-05-09",
+		Gender:     "M",
+		Education:  "Superior",
+		BirthState: "MT",
+		BirthCity:  "Cuiabá",
+		PhotoURL:   "https://www.camara.leg.br/internet/deputado/bandep/204554.jpg",
+		Email:      "dep.abiliobrunini@camara.leg.br",
+		Cabinet:    "614",
+		Building:   "4",
+		Phone:      "3215-5614",
+	}, deputy.Profile)
+}
+
+func TestAttachVotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/votacoes":
+			assert.Equal(t, "2024-01-01", r.URL.Query().Get("dataInicio"))
+			assert.Equal(t, "2024-01-31", r.URL.Query().Get("dataFim"))
+			fmt.Fprint(w, `{"dados": [{"id": "1-1"}, {"id": "1-2"}, {"id": "1-3"}], "links": []}`)
+		case "/votacoes/1-1/votos":
+			fmt.Fprint(w, `{"dados": [{"tipoVoto": "Sim", "deputado_": {"id": 204554}}, {"tipoVoto": "Não", "deputado_": {"id": 1}}]}`)
+		case "/votacoes/1-2/votos":
+			fmt.Fprint(w, `{"dados": [{"tipoVoto": "Obstrução", "deputado_": {"id": 204554}}]}`)
+		default:
+			fmt.Fprint(w, `{"dados": []}`)
+		}
+	}))
+	defer server.Close()
+
+	apiURL, months = server.URL, []int{1}
+	defer func() {
+		apiURL, months = "https://dadosabertos.camara.leg.br/api/v2", nil
+		votesLoaded = false
+	}()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, attachVotes(context.Background(), deputy))
+
+	assert.Equal(t, &Votes{RollCalls: 3, Yes: 1, Obstruction: 1, Participated: 2, ParticipationRate: 2.0 / 3}, deputy.Votes)
+
+	absent := &Deputy{ID: "2"}
+	require.NoError(t, attachVotes(context.Background(), absent))
+	assert.Equal(t, &Votes{RollCalls: 3}, absent.Votes)
+}
+
+func TestFetchCommittees(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgaos":
+			fmt.Fprint(w, `{"dados": [{"id": 2003, "sigla": "CCJC"}, {"id": 2004, "sigla": "CFT"}]}`)
+		case "/deputados/204554/orgaos":
+			fmt.Fprint(w, `{"dados": [
+				{"idOrgao": 2003, "siglaOrgao": "CCJC", "nomeOrgao": "Comissão de Constituição e Justiça e de Cidadania", "titulo": "Titular"},
+				{"idOrgao": 537480, "siglaOrgao": "PL283223", "nomeOrgao": "Comissão Especial", "titulo": "Titular"},
+				{"idOrgao": 2004, "siglaOrgao": "CFT", "nomeOrgao": "Comissão de Finanças e Tributação", "titulo": "Presidente"}
+			]}`)
+		}
+	}))
+	defer server.Close()
+
+	apiURL = server.URL
+	defer func() {
+		apiURL = "https://dadosabertos.camara.leg.br/api/v2"
+		committeesLoaded = false
+	}()
+
+	deputy := &Deputy{ID: "204554", Total: 10}
+	require.NoError(t, fetchCommittees(context.Background(), deputy))
+
+	assert.Equal(t, []Committee{
+		{Acronym: "CCJC", Name: "Comissão de Constituição e Justiça e de Cidadania", Role: "Titular"},
+		{Acronym: "CFT", Name: "Comissão de Finanças e Tributação", Role: "Presidente", Chair: true},
+	}, deputy.Committees)
+	assert.Equal(t, map[string]float64{"CCJC": 10, "CFT": 10}, committeeTotals([]*Deputy{deputy}))
+}
+
+func TestFetchProductivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proposicoes", r.URL.Path)
+		assert.Equal(t, "204554", r.URL.Query().Get("idDeputadoAutor"))
+		fmt.Fprint(w, `{"dados": [
+			{"id": 1, "siglaTipo": "PL"},
+			{"id": 2, "siglaTipo": "PEC"},
+			{"id": 3, "siglaTipo": "EMC"},
+			{"id": 4, "siglaTipo": "REQ"}
+		]}`)
+	}))
+	defer server.Close()
+
+	apiURL = server.URL
+	defer func() { apiURL = "https://dadosabertos.camara.leg.br/api/v2" }()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, fetchProductivity(context.Background(), deputy))
+
+	deputy.Total = 1000
+	prorate(deputy)
+
+	assert.Equal(t, &Productivity{Propositions: 4, Bills: 2, Amendments: 1, CostPerProposition: 250}, deputy.Productivity)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var archivePath string
+
+// writeArchive bundles the files written in this run and the manifest into a zip.
+// It is written next to its destination and renamed so readers never see a partial archive.
+func writeArchive() error {
+	tmp, err := os.CreateTemp(filepath.Dir(archivePath), ".godeputy-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := zip.NewWriter(tmp)
+	for _, name := range append(outputFiles, manifestName()) {
+		if err := addArchiveFile(w, name); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), archivePath)
+}
+
+func addArchiveFile(w *zip.Writer, name string) error {
+	f, err := os.Open(filepath.Join(outputDir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	header.Method = zip.Deflate
+
+	dst, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, f)
+
+	return err
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SourceFactory creates the Source of a legislative house for a run.
+type SourceFactory func() Source
+
+// sourceFactories holds the houses selectable with -source, added with RegisterSource.
+var sourceFactories = map[string]SourceFactory{}
+
+// house is the legislative house selected with -source; camara runs the Câmara scrape itself.
+var house = "camara"
+
+// Source is a legislative house whose members and costs can be collected. Members are
+// stored as deputies, whatever the house calls them, to share every writer.
+type Source interface {
+	// ListMembers returns the members of the house in the legislature.
+	ListMembers(ctx context.Context) ([]*Deputy, error)
+	// FetchDetails fills the figures of member for the year and the months of -mes.
+	FetchDetails(ctx context.Context, member *Deputy) error
+}
+
+// RegisterSource makes a legislative house selectable as -source name. It is meant to be
+// called from the init function of the file adding the house, as senado.go and alesp.go
+// do, and panics when name is registered twice.
+func RegisterSource(name string, factory SourceFactory) {
+	if _, ok := sourceFactories[name]; ok {
+		panic(fmt.Sprintf("source %q registered twice", name))
+	}
+
+	sourceFactories[name] = factory
+}
+
+// registeredSources lists the names given to RegisterSource, sorted.
+func registeredSources() []string {
+	var names []string
+	for name := range sourceFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// camaraSource collects the Câmara deputies one at a time, through the same listing and
+// detail visits as the scrape, for the callers that only know about Source.
+type camaraSource struct{}
+
+func init() {
+	RegisterSource("camara", func() Source { return camaraSource{} })
+}
+
+func (camaraSource) ListMembers(ctx context.Context) ([]*Deputy, error) {
+	return discoverDeputies(ctx)
+}
+
+func (camaraSource) FetchDetails(ctx context.Context, member *Deputy) error {
+	if err := collectDeputyDetails(ctx, member); err != nil {
+		return err
+	}
+	enrichDeputy(ctx, member)
+
+	return nil
+}
+
+// runSource collects every member of source and writes the outputs into dir under -out.
+// Members whose details fail are logged and skipped, reported as a partial failure.
+func runSource(ctx context.Context, source Source, dir string) error {
+	members, err := source.ListMembers(ctx)
+	if err != nil {
+		return err
+	}
+
+	baseDir := outputDir
+	defer func() {
+		outputDir = baseDir
+	}()
+	outputDir = filepath.Join(baseDir, dir)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	resetResults()
+	failedDeputies.Store(0)
+
+	var collected []*Deputy
+	for _, member := range selectDeputies(members) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := source.FetchDetails(ctx, member); err != nil {
+			errorf("member %s: %v", member.ID, err)
+			failedDeputies.Add(1)
+			continue
+		}
+
+		member.Legislature, member.Year = legislatury, year
+		member.Total = deputyTotal(member)
+		member.ScrapedAt = time.Now().UTC()
+		collected = append(collected, member)
+	}
+
+	aggregateDeputies(collected)
+	sortDeputies()
+
+	if err := writePoliticalPartyMap(); err != nil {
+		return err
+	}
+
+	if failed := failedDeputies.Load(); failed > 0 {
+		return &partialFailureError{Failed: int(failed)}
+	}
+
+	return nil
+}
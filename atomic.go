@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// backupOutputs keeps the previous version of every output file a run replaces as .bak.
+var backupOutputs bool
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path,
+// so readers see either the previous file or the complete new one, never a truncated one.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	if backupOutputs {
+		if err := backupFile(path); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// backupFile keeps a copy of path as path.bak, replacing the backup of an earlier run.
+// The file is linked rather than moved so it never goes missing before its replacement
+// is renamed in place.
+func backupFile(path string) error {
+	bak := path + ".bak"
+	if err := os.Remove(bak); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	err := os.Link(path, bak)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	// not every file system supports hard links
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(bak, data, 0644)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+var (
+	// withAttendance visits the plenary attendance page of every deputy.
+	withAttendance bool
+
+	attendanceSelector selector.QueryString = "section.presencas li.presencas__item"
+
+	daysRegex = regexp.MustCompile(`(\d+)\D*$`)
+)
+
+type Attendance struct {
+	SessionDays         int     `json:"sessionDays"`
+	Present             int     `json:"present"`
+	JustifiedAbsences   int     `json:"justifiedAbsences"`
+	UnjustifiedAbsences int     `json:"unjustifiedAbsences"`
+	Rate                float64 `json:"rate"`
+}
+
+func attendanceURL(id string) string {
+	return fmt.Sprintf("%s/deputados/%s/presenca-plenario/%d", baseURL, id, year)
+}
+
+// visitAttendance reads the plenary attendance of deputy in the year, whose lines hold a
+// label such as "Ausências justificadas" followed by a number of days.
+func visitAttendance(ctx context.Context, deputy *Deputy) error {
+	if perRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, perRequestTimeout)
+		defer cancel()
+	}
+
+	attendance := &Attendance{}
+	found := false
+
+	c := newCollector(ctx)
+
+	c.OnRequest(func(req *http.Request) error {
+		debugf("visit %s", req.URL)
+
+		return nil
+	})
+
+	c.OnNode(attendanceSelector, func(req *http.Request, resp *http.Response, node *html.Node) error {
+		text := nodeText(node)
+		strs := daysRegex.FindStringSubmatch(text)
+		if len(strs) == 0 {
+			return nil
+		}
+		days, _ := strconv.Atoi(strs[1])
+
+		label := strings.ToLower(text)
+		switch {
+		case strings.Contains(label, "sess"):
+			attendance.SessionDays = days
+		case strings.Contains(label, "não justificada"):
+			attendance.UnjustifiedAbsences = days
+		case strings.Contains(label, "justificada"):
+			attendance.JustifiedAbsences = days
+		case strings.Contains(label, "presen"):
+			attendance.Present = days
+		default:
+			return nil
+		}
+		found = true
+
+		return nil
+	})
+
+	if err := c.Visit(attendanceURL(deputy.ID)); err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("error.attendance.not.found")
+	}
+
+	if attendance.SessionDays > 0 {
+		attendance.Rate = float64(attendance.Present) / float64(attendance.SessionDays)
+	}
+	deputy.Attendance = attendance
+
+	return nil
+}
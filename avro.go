@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// deputyAvroSchema and expenseAvroSchema have the columns of deputies.parquet and
+// expenses.parquet. They are embedded in every file, so consumers need no registry.
+var (
+	deputyAvroSchema  = avroSchema("Deputy", deputyColumns)
+	expenseAvroSchema = avroSchema("Expense", expenseColumns)
+)
+
+// avroTypes are the Avro types of the column types.
+var avroTypes = map[columnType]any{
+	columnString: "string",
+	columnInt:    "int",
+	columnMoney:  "double",
+	columnBool:   "boolean",
+	columnTime:   map[string]string{"type": "long", "logicalType": "timestamp-millis"},
+}
+
+type avroField struct {
+	Name    string `json:"name"`
+	Type    any    `json:"type"`
+	Default any    `json:"default,omitempty"`
+}
+
+func avroSchema(name string, columns []tableColumn) string {
+	fields := make([]avroField, 0, len(columns))
+	for _, c := range columns {
+		field := avroField{Name: c.Name, Type: avroTypes[c.Type]}
+		if c.Optional {
+			field.Type = []any{"null", field.Type}
+			field.Default = json.RawMessage("null")
+		}
+		fields = append(fields, field)
+	}
+
+	schema, err := json.Marshal(map[string]any{
+		"type":      "record",
+		"name":      name,
+		"namespace": "com.github.m2tx.godeputy",
+		"fields":    fields,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return string(schema)
+}
+
+// avroValue converts a value of a table row into what goavro expects for its column.
+func avroValue(c tableColumn, v any) any {
+	switch {
+	case c.Type == columnInt:
+		return int32(v.(int))
+	case c.Optional && v == "":
+		return nil
+	case c.Optional:
+		return goavro.Union(avroTypes[c.Type].(string), v)
+	}
+
+	return v
+}
+
+func deputyAvroRecords(deputies []*Deputy) []any {
+	records := make([]any, 0, len(deputies))
+	for _, d := range deputies {
+		records = append(records, tableRecord(deputyColumns, deputyRow(d), avroValue))
+	}
+
+	return records
+}
+
+func expenseAvroRecords(deputies []*Deputy) []any {
+	var records []any
+	for _, d := range deputies {
+		for _, e := range expenseRows(d) {
+			records = append(records, tableRecord(expenseColumns, expenseRow(d, e), avroValue))
+		}
+	}
+
+	return records
+}
+
+// encodeAvro writes the records into a deflate compressed object container file.
+func encodeAvro(schema string, records []any) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               &buffer,
+		Schema:          schema,
+		CompressionName: goavro.CompressionDeflateLabel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.Append(records); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeAvro() error {
+	bytes, err := encodeAvro(deputyAvroSchema, deputyAvroRecords(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutputFile("deputies.avro", bytes); err != nil {
+		return err
+	}
+
+	bytes, err = encodeAvro(expenseAvroSchema, expenseAvroRecords(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("expenses.avro", bytes)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const bigqueryScope = "https://www.googleapis.com/auth/bigquery"
+
+var (
+	// bigqueryProject, bigqueryDataset and bigqueryTable name the table every flushed batch
+	// is streamed into. The project defaults to the one of the credentials.
+	bigqueryProject string
+	bigqueryDataset string
+	bigqueryTable   string
+
+	bigqueryURL = "https://bigquery.googleapis.com/bigquery/v2/"
+
+	newBigQueryClient = func(ctx context.Context) (*http.Client, string, error) {
+		return googleClient(ctx, bigqueryScope)
+	}
+
+	bigqueryOnce sync.Once
+	bigqueryAPI  *googleAPI
+	bigqueryErr  error
+)
+
+// bigqueryTypes are the BigQuery types of the column types.
+var bigqueryTypes = map[columnType]string{
+	columnString: "STRING",
+	columnInt:    "INTEGER",
+	columnMoney:  "NUMERIC",
+	columnBool:   "BOOLEAN",
+	columnTime:   "TIMESTAMP",
+}
+
+// bigqueryFields is the schema the table is created with when it does not exist. The
+// quota expenses are nested in each deputy, down to the documents with -documents.
+var bigqueryFields = append(bigquerySchema(deputyColumns), map[string]any{
+	"name": "expenses", "type": "RECORD", "mode": "REPEATED", "fields": bigquerySchema(expenseDetailColumns),
+})
+
+func bigquerySchema(columns []tableColumn) []map[string]any {
+	fields := make([]map[string]any, 0, len(columns))
+	for _, c := range columns {
+		field := map[string]any{"name": c.Name, "type": bigqueryTypes[c.Type]}
+		if c.Key {
+			field["mode"] = "REQUIRED"
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// bigqueryValue converts a value of a table row into its JSON in the table, with the
+// numbers as strings so the NUMERIC columns keep their cents exactly.
+func bigqueryValue(c tableColumn, v any) any {
+	switch {
+	case c.Type == columnMoney:
+		return formatCSVFloat(v.(float64))
+	case c.Type == columnTime:
+		return v.(time.Time).UTC().Format(time.RFC3339)
+	case c.Optional && v == "":
+		return nil
+	}
+
+	return v
+}
+
+// bigqueryRow is the JSON of deputy in the table.
+func bigqueryRow(d *Deputy) map[string]any {
+	var expenses []map[string]any
+	for _, e := range expenseRows(d) {
+		expenses = append(expenses, tableRecord(expenseDetailColumns, expenseDetailRow(e), bigqueryValue))
+	}
+
+	row := tableRecord(deputyColumns, deputyRow(d), bigqueryValue)
+	row["expenses"] = expenses
+
+	return row
+}
+
+// setupBigQuery authenticates once for the run and creates the table when it is missing.
+func setupBigQuery(ctx context.Context) (*googleAPI, error) {
+	bigqueryOnce.Do(func() {
+		client, project, err := newBigQueryClient(ctx)
+		if err != nil {
+			bigqueryErr = fmt.Errorf("error.bigquery.credentials: %v", err)
+			return
+		}
+		if bigqueryProject != "" {
+			project = bigqueryProject
+		}
+		if project == "" {
+			bigqueryErr = fmt.Errorf("error.bigquery.project: the credentials have no project, set -bigquery-project")
+			return
+		}
+
+		datasetURL := bigqueryURL + "projects/" + url.PathEscape(project) + "/datasets/" + url.PathEscape(bigqueryDataset) + "/tables"
+		api := &googleAPI{client: client, url: datasetURL}
+
+		var apiErr *googleAPIError
+		err = api.call(ctx, http.MethodGet, "/"+url.PathEscape(bigqueryTable), nil, nil)
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			infof("creating bigquery table %s.%s.%s", project, bigqueryDataset, bigqueryTable)
+
+			err = api.call(ctx, http.MethodPost, "", map[string]any{
+				"tableReference": map[string]any{"projectId": project, "datasetId": bigqueryDataset, "tableId": bigqueryTable},
+				"schema":         map[string]any{"fields": bigqueryFields},
+			}, nil)
+		}
+		if err != nil {
+			bigqueryErr = fmt.Errorf("error.bigquery.table: %v", err)
+			return
+		}
+
+		api.url += "/" + url.PathEscape(bigqueryTable)
+		bigqueryAPI = api
+	})
+
+	return bigqueryAPI, bigqueryErr
+}
+
+// writeBigQuery streams the flushed deputies into the table. The insert IDs make BigQuery
+// drop the rows a retried batch sends again.
+func writeBigQuery(ctx context.Context, deputies []*Deputy) error {
+	api, err := setupBigQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]map[string]any, 0, len(deputies))
+	for _, d := range deputies {
+		rows = append(rows, map[string]any{
+			"insertId": fmt.Sprintf("%d-%d-%s-%d", d.Legislature, d.Year, d.ID, d.ScrapedAt.UnixNano()),
+			"json":     bigqueryRow(d),
+		})
+	}
+
+	var response struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if err := api.call(ctx, http.MethodPost, "/insertAll", map[string]any{"rows": rows}, &response); err != nil {
+		return fmt.Errorf("error.bigquery.insert: %v", err)
+	}
+
+	if n := len(response.InsertErrors); n > 0 {
+		first, message := response.InsertErrors[0], ""
+		if len(first.Errors) > 0 {
+			message = first.Errors[0].Message
+		}
+		return fmt.Errorf("error.bigquery.insert: %d of %d rows rejected, the first at index %d: %s", n, len(rows), first.Index, message)
+	}
+
+	return nil
+}
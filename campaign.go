@@ -0,0 +1,338 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// withCampaign joins every deputy with the campaign accounts it filed with the TSE for
+	// the election of the legislature.
+	withCampaign bool
+
+	// tseURL is the campaign accounts dump of the candidates of an election, published by the TSE.
+	tseURL = "https://cdn.tse.jus.br/estatistica/sead/odsele/prestacao_contas/prestacao_de_contas_eleitorais_candidatos_%d.zip"
+
+	// tseFile reads the campaign accounts dump from disk instead of downloading it.
+	tseFile string
+)
+
+type Campaign struct {
+	ElectionYear int     `json:"electionYear"`
+	Revenue      float64 `json:"revenue"`
+	Expenses     float64 `json:"expenses"`
+	MatchedBy    string  `json:"matchedBy"`
+}
+
+// tseCandidate sums the revenue and contracted expenses a candidate declared.
+type tseCandidate struct {
+	CPF      string
+	Name     string
+	State    string
+	Revenue  float64
+	Expenses float64
+}
+
+// tseAccounts indexes the federal deputy candidates of the dump by CPF and by name and state.
+type tseAccounts struct {
+	ElectionYear int
+	byCPF        map[string]*tseCandidate
+	byName       map[string]*tseCandidate
+}
+
+var (
+	campaignMutex    sync.Mutex
+	campaignLoaded   bool
+	campaignElection int
+	campaignAccounts *tseAccounts
+	campaignErr      error
+)
+
+// electionYear is the year of the general election that chose the legislature.
+func electionYear(legislature int) int {
+	first, _ := legislatureYears(legislature)
+
+	return first - 1
+}
+
+// loadCampaignAccounts reads the dump of the election of the legislature being scraped,
+// once for each election the run goes through.
+func loadCampaignAccounts(ctx context.Context) (*tseAccounts, error) {
+	campaignMutex.Lock()
+	defer campaignMutex.Unlock()
+
+	election := electionYear(legislatury)
+	if !campaignLoaded || campaignElection != election {
+		campaignAccounts, campaignErr = readCampaignAccounts(ctx, election)
+		campaignLoaded, campaignElection = true, election
+	}
+
+	return campaignAccounts, campaignErr
+}
+
+func readCampaignAccounts(ctx context.Context, election int) (*tseAccounts, error) {
+	name := tseFile
+	if name == "" {
+		name = fmt.Sprintf(tseURL, election)
+	}
+
+	data, err := readDump(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTSEDump(data, election)
+}
+
+// parseTSEDump sums the receitas_candidatos and despesas_contratadas_candidatos files of each
+// state in the zip archive of an election, skipping the BRASIL files that repeat all of them.
+func parseTSEDump(data []byte, election int) (*tseAccounts, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error.tse.zip: %v", err)
+	}
+
+	accounts := &tseAccounts{
+		ElectionYear: election,
+		byCPF:        map[string]*tseCandidate{},
+		byName:       map[string]*tseCandidate{},
+	}
+
+	for _, f := range archive.File {
+		name := strings.ToLower(path.Base(f.Name))
+		if path.Ext(name) != ".csv" || strings.Contains(name, "_brasil") {
+			continue
+		}
+
+		var column string
+		switch {
+		case strings.HasPrefix(name, "receitas_candidatos_"):
+			column = "VR_RECEITA"
+		case strings.HasPrefix(name, "despesas_contratadas_candidatos_"):
+			column = "VR_DESPESA_CONTRATADA"
+		default:
+			continue
+		}
+
+		if err := accounts.addFile(f, column); err != nil {
+			return nil, fmt.Errorf("error.tse.%s: %v", name, err)
+		}
+	}
+
+	return accounts, nil
+}
+
+func (a *tseAccounts) addFile(f *zip.File, column string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader = bytes.NewReader(data)
+	if !utf8.Valid(data) {
+		reader = charmap.ISO8859_1.NewDecoder().Reader(reader)
+	}
+
+	records := csv.NewReader(reader)
+	records.Comma = ';'
+	records.LazyQuotes = true
+	records.FieldsPerRecord = -1
+
+	header, err := records.Read()
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"DS_CARGO", "SG_UF", "NR_CPF_CANDIDATO", "NM_CANDIDATO", column} {
+		if _, ok := index[name]; !ok {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			if i := index[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		if !strings.EqualFold(field("DS_CARGO"), "Deputado Federal") {
+			continue
+		}
+
+		// rows without a CPF cannot be told apart, so they would all add up in one candidate
+		if digitsOnly(field("NR_CPF_CANDIDATO")) == "" {
+			continue
+		}
+
+		value, err := parseCEAPValue(field(column))
+		if err != nil {
+			warnf("skipping %s of candidate %s: %v", column, field("NM_CANDIDATO"), err)
+			continue
+		}
+
+		candidate := a.candidate(field("NR_CPF_CANDIDATO"), field("NM_CANDIDATO"), field("SG_UF"))
+		if column == "VR_RECEITA" {
+			candidate.Revenue += value
+		} else {
+			candidate.Expenses += value
+		}
+	}
+}
+
+func (a *tseAccounts) candidate(cpf, name, state string) *tseCandidate {
+	cpf = digitsOnly(cpf)
+	if c, ok := a.byCPF[cpf]; ok {
+		return c
+	}
+
+	c := &tseCandidate{CPF: cpf, Name: name, State: state}
+	a.byCPF[cpf] = c
+	a.byName[candidateKey(name, state)] = c
+
+	return c
+}
+
+// find looks deputy up by the CPF of its profile and, failing that, by its civil or
+// parliamentary name in its state.
+func (a *tseAccounts) find(deputy *Deputy) (*tseCandidate, string) {
+	if p := deputy.Profile; p != nil && p.CPF != "" {
+		if c, ok := a.byCPF[digitsOnly(p.CPF)]; ok {
+			return c, "cpf"
+		}
+	}
+
+	names := []string{deputy.Name}
+	if p := deputy.Profile; p != nil && p.CivilName != "" {
+		names = append([]string{p.CivilName}, names...)
+	}
+
+	for _, name := range names {
+		if c, ok := a.byName[candidateKey(name, deputy.State)]; ok {
+			return c, "name"
+		}
+	}
+
+	return nil, ""
+}
+
+// candidateKey compares names without accents, case or repeated spaces.
+func candidateKey(name, state string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToUpper(strings.Join(strings.Fields(name), " "))) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.ToUpper(state) + "|" + b.String()
+}
+
+func digitsOnly(v string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, v)
+}
+
+// fetchCampaign attaches the campaign accounts deputy filed for the election of the legislature.
+func fetchCampaign(ctx context.Context, deputy *Deputy) error {
+	accounts, err := loadCampaignAccounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidate, matchedBy := accounts.find(deputy)
+	if candidate == nil {
+		return fmt.Errorf("error.tse.candidate.not.found: %s (%s)", deputy.Name, deputy.State)
+	}
+
+	deputy.Campaign = &Campaign{
+		ElectionYear: accounts.ElectionYear,
+		Revenue:      candidate.Revenue,
+		Expenses:     candidate.Expenses,
+		MatchedBy:    matchedBy,
+	}
+
+	return nil
+}
+
+type campaignComparison struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	PoliticalParty   string  `json:"politicalParty"`
+	State            string  `json:"state"`
+	CampaignRevenue  float64 `json:"campaignRevenue"`
+	CampaignExpenses float64 `json:"campaignExpenses"`
+	MandateTotal     float64 `json:"mandateTotal"`
+}
+
+// campaignComparisons lists the campaign expenses of each matched deputy next to what its
+// mandate cost in the period, the most expensive campaigns first.
+func campaignComparisons(deputies []*Deputy) []campaignComparison {
+	var comparisons []campaignComparison
+	for _, d := range deputies {
+		if d.Campaign == nil {
+			continue
+		}
+
+		comparisons = append(comparisons, campaignComparison{
+			ID:               d.ID,
+			Name:             d.Name,
+			PoliticalParty:   d.PoliticalParty,
+			State:            d.State,
+			CampaignRevenue:  d.Campaign.Revenue,
+			CampaignExpenses: d.Campaign.Expenses,
+			MandateTotal:     d.Total,
+		})
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].CampaignExpenses > comparisons[j].CampaignExpenses
+	})
+
+	return comparisons
+}
+
+func writeCampaignComparison() error {
+	bytes, err := encodeOutputJSON(campaignComparisons(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("campaign_vs_mandate.json", bytes)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSEDump(t *testing.T) {
+	files := map[string]string{
+		"receitas_candidatos_2022_SP.csv": `"SG_UF";"DS_CARGO";"NR_CPF_CANDIDATO";"NM_CANDIDATO";"VR_RECEITA"
+"SP";"Deputado Federal";"12345678901";"MARIA DA SILVA";"1000,50"
+"SP";"Deputado Federal";"12345678901";"MARIA DA SILVA";"500"
+"SP";"Deputado Estadual";"98765432100";"JOÃO SOUZA";"700"
+"SP";"Deputado Federal";"";"JOSÉ SEM CPF";"300"
+"RJ";"Deputado Federal";"#NULO#";"ANA SEM CPF";"400"
+`,
+		"despesas_contratadas_candidatos_2022_SP.csv": `"SG_UF";"DS_CARGO";"NR_CPF_CANDIDATO";"NM_CANDIDATO";"VR_DESPESA_CONTRATADA"
+"SP";"DEPUTADO FEDERAL";"12345678901";"MARIA DA SILVA";"1200,25"
+`,
+		"receitas_candidatos_2022_BRASIL.csv": `"SG_UF";"DS_CARGO";"NR_CPF_CANDIDATO";"NM_CANDIDATO";"VR_RECEITA"
+"SP";"Deputado Federal";"12345678901";"MARIA DA SILVA";"1500,50"
+`,
+	}
+
+	var buffer bytes.Buffer
+	w := zip.NewWriter(&buffer)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	accounts, err := parseTSEDump(buffer.Bytes(), 2022)
+	require.NoError(t, err)
+
+	candidate, matchedBy := accounts.find(&Deputy{Name: "Maria da Silva", State: "SP"})
+	require.NotNil(t, candidate)
+	assert.Equal(t, "name", matchedBy)
+	assert.Equal(t, 1500.5, candidate.Revenue)
+	assert.Equal(t, 1200.25, candidate.Expenses)
+
+	_, matchedBy = accounts.find(&Deputy{Name: "Maria", State: "SP", Profile: &DeputyProfile{CPF: "123.456.789-01"}})
+	assert.Equal(t, "cpf", matchedBy)
+
+	candidate, _ = accounts.find(&Deputy{Name: "João Souza", State: "SP"})
+	assert.Nil(t, candidate)
+
+	assert.NotContains(t, accounts.byCPF, "")
+	candidate, _ = accounts.find(&Deputy{Name: "José sem CPF", State: "SP"})
+	assert.Nil(t, candidate)
+}
+
+func TestLoadCampaignAccounts(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, zip.NewWriter(&buffer).Close())
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write(buffer.Bytes())
+	}))
+	defer server.Close()
+
+	defer func(url string, legislature int) {
+		tseURL, legislatury = url, legislature
+		campaignLoaded = false
+	}(tseURL, legislatury)
+	tseURL, campaignLoaded = server.URL+"/%d.zip", false
+
+	ctx := context.Background()
+	for _, legislature := range []int{56, 56, 57} {
+		legislatury = legislature
+		accounts, err := loadCampaignAccounts(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, electionYear(legislature), accounts.ElectionYear)
+	}
+
+	assert.Equal(t, []string{"/2018.zip", "/2022.zip"}, requested)
+}
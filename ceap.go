@@ -0,0 +1,238 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ceapURL is the yearly parliamentary quota (CEAP) dump published by the Câmara.
+	ceapURL = "https://www.camara.leg.br/cotas/Ano-%d.csv.zip"
+
+	// ceapFile reads the dump from disk, zipped or not, instead of downloading it.
+	ceapFile string
+)
+
+var ceapColumns = []string{"ideCadastro", "txNomeParlamentar", "sgPartido", "sgUF", "txtDescricao", "vlrLiquido", "numMes", "numAno"}
+
+// runCEAP builds every output from the CEAP dump of the year, without visiting any deputy page.
+func runCEAP(ctx context.Context) error {
+	name := ceapFile
+	if name == "" {
+		name = fmt.Sprintf(ceapURL, year)
+	}
+
+	data, err := readDump(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	deputies, err := parseCEAPDump(data, name)
+	if err != nil {
+		return err
+	}
+
+	resetResults()
+	aggregateDeputies(selectDeputies(deputies))
+	sortDeputies()
+
+	return writePoliticalPartyMap()
+}
+
+// readDump reads an open data dump from disk or, given an URL, downloads it.
+func readDump(ctx context.Context, name string) ([]byte, error) {
+	if !strings.HasPrefix(name, "http://") && !strings.HasPrefix(name, "https://") {
+		return os.ReadFile(name)
+	}
+
+	infof("downloading %s", name)
+
+	req, err := http.NewRequest(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := (&contextClient{ctx: ctx, client: httpClient}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error.dump.download: %s %s", name, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// parseCEAPDump reads the CSV of a dump, opening the zip archive it comes in when needed.
+func parseCEAPDump(data []byte, name string) ([]*Deputy, error) {
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		return parseCEAPCSV(bytes.NewReader(data), name)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error.ceap.zip: %v", err)
+	}
+
+	for _, f := range archive.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".csv") {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error.ceap.zip: %v", err)
+		}
+		defer r.Close()
+
+		return parseCEAPCSV(r, name)
+	}
+
+	return nil, fmt.Errorf("error.ceap.zip: no csv file in %s", name)
+}
+
+// parseCEAPCSV groups the expense rows of the dump by deputy, summing the net value
+// of each expense category. Rows of party leaderships, which have no deputy ID, are skipped.
+func parseCEAPCSV(r io.Reader, sourceURL string) ([]*Deputy, error) {
+	buffered := bufio.NewReader(r)
+	if bom, _ := buffered.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error.ceap.header: %v", err)
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.TrimSpace(column)] = i
+	}
+	for _, column := range ceapColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("error.ceap.header: missing column %q", column)
+		}
+	}
+
+	var (
+		deputies  []*Deputy
+		byID      = map[string]*Deputy{}
+		scrapedAt = time.Now().UTC()
+	)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error.ceap.line.%d: %v", line, err)
+		}
+
+		field := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		id := field("ideCadastro")
+		if id == "" {
+			continue
+		}
+
+		if y, err := strconv.Atoi(field("numAno")); err == nil && y != year {
+			continue
+		}
+		if len(months) > 0 {
+			if m, err := strconv.Atoi(field("numMes")); err != nil || !containsMonth(months, m) {
+				continue
+			}
+		}
+
+		value, err := parseCEAPValue(field("vlrLiquido"))
+		if err != nil {
+			warnf("skipping line %d of %s: %v", line, sourceURL, err)
+			continue
+		}
+
+		deputy, ok := byID[id]
+		if !ok {
+			deputy = &Deputy{
+				ID:             id,
+				Name:           field("txNomeParlamentar"),
+				PoliticalParty: field("sgPartido"),
+				State:          field("sgUF"),
+				Legislature:    legislatury,
+				Year:           year,
+				SourceURL:      sourceURL,
+				ScrapedAt:      scrapedAt,
+				MissingFields:  append([]string(nil), portalOnlyFields...),
+			}
+			byID[id] = deputy
+			deputies = append(deputies, deputy)
+		}
+
+		addDeputyFigures(deputy, quotaExpense(field("txtDescricao"), value, CostDetail{
+			Date:           field("datEmissao"),
+			SupplierName:   field("txtFornecedor"),
+			SupplierCNPJ:   field("txtCNPJCPF"),
+			DocumentNumber: field("txtNumero"),
+			DocumentURL:    field("urlDocumento"),
+		}))
+	}
+
+	for _, d := range deputies {
+		d.Total = d.ParliamentaryQuota
+	}
+
+	return deputies, nil
+}
+
+// parseCEAPValue reads the dump amounts, written with a bare decimal point in recent
+// years and in the Brazilian format, e.g. 1.234,56, in older ones and in the Senado,
+// ALESP and TSE dumps, which go through parseMoney.
+func parseCEAPValue(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if !strings.Contains(v, ",") {
+		if value, err := strconv.ParseFloat(v, bitSize); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := parseMoney(v)
+	if err != nil {
+		return 0, fmt.Errorf("error.ceap.value: %q", v)
+	}
+
+	return value, nil
+}
+
+func containsMonth(months []int, month int) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+
+	return false
+}
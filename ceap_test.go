@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCEAPCSV(t *testing.T) {
+	dump := "\ufeff" + `"txNomeParlamentar";"ideCadastro";"sgUF";"sgPartido";"txtDescricao";"vlrLiquido";"numMes";"numAno"
+"Abilio Brunini";"204554";"MT";"PL";"COMBUSTÍVEIS E LUBRIFICANTES.";"150.50";"1";"2024"
+"LIDERANÇA DO PT";"";"";"";"TELEFONIA";"99";"1";"2024"
+"Abilio Brunini";"204554";"MT";"PL";"TELEFONIA";"20";"2";"2024"
+"Abilio Brunini";"204554";"MT";"PL";"COMBUSTÍVEIS E LUBRIFICANTES.";"49,50";"3";"2024"
+"Abilio Brunini";"204554";"MT";"PL";"TELEFONIA";"1.234,56";"3";"2024"
+"Abilio Brunini";"204554";"MT";"PL";"TELEFONIA";"n/d";"4";"2024"
+`
+
+	deputies, err := parseCEAPCSV(strings.NewReader(dump), "Ano-2024.csv")
+	require.NoError(t, err)
+	require.Len(t, deputies, 1)
+
+	d := deputies[0]
+	assert.Equal(t, "204554", d.ID)
+	assert.Equal(t, "PL", d.PoliticalParty)
+	assert.InDelta(t, 1454.56, d.ParliamentaryQuota, 0.001)
+	assert.Equal(t, d.ParliamentaryQuota, d.Total)
+	assert.Equal(t, []CostDetail{
+		{Description: "COMBUSTÍVEIS E LUBRIFICANTES.", Value: 200},
+		{Description: "TELEFONIA", Value: 1254.56},
+	}, d.ParliamentaryQuotaDetails)
+	assert.Equal(t, portalOnlyFields, d.MissingFields)
+
+	_, err = parseCEAPCSV(strings.NewReader(`"ideCadastro";"vlrLiquido"`), "Ano-2024.csv")
+	assert.Error(t, err)
+}
+
+func TestParseCEAPValue(t *testing.T) {
+	for v, expected := range map[string]float64{
+		"":          0,
+		"150.50":    150.5,
+		"99":        99,
+		"49,50":     49.5,
+		"1.234,56":  1234.56,
+		"-1.234,56": -1234.56,
+		"1.234.567": 1234567,
+	} {
+		value, err := parseCEAPValue(v)
+		require.NoError(t, err, v)
+		assert.InDelta(t, expected, value, 0.001, v)
+	}
+
+	_, err := parseCEAPValue("n/d")
+	assert.Error(t, err)
+}
+
+func TestParseCEAPCSVDocuments(t *testing.T) {
+	withDocuments = true
+	defer func() {
+		withDocuments = false
+	}()
+
+	dump := `"txNomeParlamentar";"ideCadastro";"sgUF";"sgPartido";"txtDescricao";"txtFornecedor";"txtCNPJCPF";"vlrLiquido";"numMes";"numAno";"urlDocumento"
+"Abilio Brunini";"204554";"MT";"PL";"TELEFONIA";"CLARO S.A.";"40432544000147";"20";"1";"2024";"https://www.camara.leg.br/cota-parlamentar/documentos/publ/3453/2024/7654321.pdf"
+"Abilio Brunini";"204554";"MT";"PL";"TELEFONIA";"TIM S.A.";"02421421000111";"30";"2";"2024";""
+`
+
+	deputies, err := parseCEAPCSV(strings.NewReader(dump), "Ano-2024.csv")
+	require.NoError(t, err)
+	require.Len(t, deputies, 1)
+
+	assert.Equal(t, []CostDetail{{
+		Description: "TELEFONIA",
+		Value:       50,
+		Documents: []CostDetail{
+			{
+				Description:  "TELEFONIA",
+				Value:        20,
+				SupplierName: "CLARO S.A.",
+				SupplierCNPJ: "40432544000147",
+				DocumentURL:  "https://www.camara.leg.br/cota-parlamentar/documentos/publ/3453/2024/7654321.pdf",
+			},
+			{Description: "TELEFONIA", Value: 30, SupplierName: "TIM S.A.", SupplierCNPJ: "02421421000111"},
+		},
+	}}, deputies[0].ParliamentaryQuotaDetails)
+}
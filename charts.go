@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	chart "github.com/wcharczuk/go-chart"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	categoryCharts bool
+	categoryTop    = 10
+
+	// chartTop is the number of deputies in top_deputies.png, none when it is zero.
+	chartTop = 20
+)
+
+type categorySpending struct {
+	Deputy *Deputy
+	Value  float64
+}
+
+// categoryFileName turns a spending category into a safe file name,
+// e.g. "COMBUSTÍVEIS E LUBRIFICANTES." becomes "combustiveis-e-lubrificantes".
+func categoryFileName(category string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range norm.NFD.String(strings.ToLower(category)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" {
+		return "category"
+	}
+
+	return name
+}
+
+func categorySpendingMap(deputies []*Deputy) map[string][]categorySpending {
+	categories := map[string][]categorySpending{}
+	for _, d := range deputies {
+		for _, detail := range d.ParliamentaryQuotaDetails {
+			categories[detail.Description] = append(categories[detail.Description], categorySpending{
+				Deputy: d,
+				Value:  detail.Value,
+			})
+		}
+	}
+
+	return categories
+}
+
+// writeCategoryCharts writes a chart per category into charts/, skipping the categories
+// that cost nothing. A chart that cannot be drawn is logged and skipped.
+func writeCategoryCharts() error {
+	for category, spending := range categorySpendingMap(deputiesArray) {
+		sort.SliceStable(spending, func(i, j int) bool {
+			return spending[i].Value > spending[j].Value
+		})
+
+		if len(spending) > categoryTop {
+			spending = spending[:categoryTop]
+		}
+		if len(spending) == 0 || spending[0].Value <= 0 {
+			continue
+		}
+
+		var bars []chart.Value
+		for _, s := range spending {
+			bars = append(bars, chart.Value{
+				Label: fmt.Sprintf("%s (%s-%s)", s.Deputy.Name, s.Deputy.PoliticalParty, s.Deputy.State),
+				Value: s.Value,
+			})
+		}
+
+		ch := chart.BarChart{
+			Title:      category,
+			TitleStyle: chart.StyleShow(),
+			Height:     512,
+			Width:      1024,
+			BarWidth:   60,
+			XAxis: chart.Style{
+				Show:                true,
+				FontSize:            8,
+				TextRotationDegrees: 45,
+			},
+			YAxis: chart.YAxis{
+				Style: chart.StyleShow(),
+				Range: &chart.ContinuousRange{
+					Min: 0,
+					Max: spending[0].Value,
+				},
+			},
+			Bars: bars,
+		}
+
+		var buffer bytes.Buffer
+		if err := ch.Render(chartRenderer(), &buffer); err != nil {
+			errorf("category chart %q: %v", category, err)
+			continue
+		}
+
+		err := writeOutputFile(fmt.Sprintf("charts/%s.%s", categoryFileName(category), chartFormat), buffer.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// topDeputiesChart draws the deputies that spent the most as horizontal bars, labelled
+// with their party and state, the most expensive on top. go-chart only draws vertical
+// bars, which leave no room for the names.
+type topDeputiesChart struct {
+	Title    string
+	Deputies []*Deputy
+}
+
+func newTopDeputiesChart(deputies []*Deputy, n int) topDeputiesChart {
+	top := append([]*Deputy(nil), deputies...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Total > top[j].Total
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+
+	return topDeputiesChart{Title: translate("chart.top.title"), Deputies: top}
+}
+
+func (c topDeputiesChart) Render(rp chart.RendererProvider, w io.Writer) error {
+	if len(c.Deputies) == 0 {
+		return errors.New("no deputies to draw")
+	}
+
+	const (
+		width     = 1024
+		rowHeight = 24
+		barHeight = 16
+		padding   = 10
+		titleSize = 14
+		labelSize = 10
+	)
+	top := padding*3 + titleSize*2
+	height := top + len(c.Deputies)*rowHeight + padding
+
+	r, err := rp(width, height)
+	if err != nil {
+		return err
+	}
+
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return err
+	}
+	r.SetDPI(chart.DefaultDPI)
+	r.SetFont(font)
+
+	r.SetFillColor(chart.ColorWhite)
+	r.MoveTo(0, 0)
+	r.LineTo(width, 0)
+	r.LineTo(width, height)
+	r.LineTo(0, height)
+	r.Close()
+	r.Fill()
+
+	labels := make([]string, len(c.Deputies))
+	values := make([]string, len(c.Deputies))
+	labelWidth, valueWidth := 0, 0
+	r.SetFontSize(labelSize)
+	for i, d := range c.Deputies {
+		labels[i] = fmt.Sprintf("%s (%s-%s)", d.Name, d.PoliticalParty, d.State)
+		values[i] = formatMoney(d.Total)
+		labelWidth = max(labelWidth, r.MeasureText(labels[i]).Width())
+		valueWidth = max(valueWidth, r.MeasureText(values[i]).Width())
+	}
+	labelWidth = min(labelWidth, width/2)
+
+	r.SetFontColor(chart.ColorBlack)
+	r.SetFontSize(titleSize)
+	title := r.MeasureText(c.Title)
+	r.Text(c.Title, (width-title.Width())/2, padding+title.Height())
+
+	// the bars of a party share its color
+	colors := map[string]int{}
+	left := padding + labelWidth + padding
+	span := width - left - padding*2 - valueWidth
+	maxTotal := c.Deputies[0].Total
+
+	r.SetFontSize(labelSize)
+	for i, d := range c.Deputies {
+		y := top + i*rowHeight
+		baseline := y + (rowHeight+r.MeasureText(labels[i]).Height())/2
+
+		r.SetFontColor(chart.ColorBlack)
+		label := labels[i]
+		for runes := []rune(label); len(runes) > 1 && r.MeasureText(label).Width() > labelWidth; {
+			runes = runes[:len(runes)-1]
+			label = string(runes) + "…"
+		}
+		r.Text(label, left-padding-r.MeasureText(label).Width(), baseline)
+
+		length := 0
+		if maxTotal > 0 {
+			length = int(float64(span) * d.Total / maxTotal)
+		}
+
+		if _, ok := colors[d.PoliticalParty]; !ok {
+			colors[d.PoliticalParty] = len(colors)
+		}
+		r.SetFillColor(chart.GetDefaultColor(colors[d.PoliticalParty]))
+		barTop := y + (rowHeight-barHeight)/2
+		r.MoveTo(left, barTop)
+		r.LineTo(left+length, barTop)
+		r.LineTo(left+length, barTop+barHeight)
+		r.LineTo(left, barTop+barHeight)
+		r.Close()
+		r.Fill()
+
+		r.Text(values[i], left+length+padding, baseline)
+	}
+
+	return r.Save(w)
+}
+
+// writeTopDeputiesChart writes top_deputies.png, the chart of the -chart-top deputies
+// that spent the most, skipped when none spent anything.
+func writeTopDeputiesChart() error {
+	if chartTop <= 0 {
+		return nil
+	}
+
+	var spent bool
+	for _, d := range deputiesArray {
+		spent = spent || d.Total > 0
+	}
+	if !spent {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	if err := newTopDeputiesChart(deputiesArray, chartTop).Render(chartRenderer(), &buffer); err != nil {
+		return fmt.Errorf("error.chart.top: %v", err)
+	}
+
+	return writeOutputFile("top_deputies."+chartFormat, buffer.Bytes())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const checkpointFile = "checkpoint.ndjson"
+
+var (
+	resume bool
+
+	// resumedDeputies holds the deputies an interrupted run already flushed, by ID.
+	resumedDeputies map[string]*Deputy
+)
+
+func checkpointPath() string {
+	return filepath.Join(outputDir, templateName(checkpointFile))
+}
+
+// openCheckpoint loads the deputies completed by an interrupted run when -resume
+// is set, and otherwise discards any stale checkpoint.
+func openCheckpoint() error {
+	resumedDeputies = map[string]*Deputy{}
+
+	if !resume {
+		if err := os.Remove(checkpointPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	// the interrupted run may have named it after another {date} or {time}
+	if previous := previousOutputPath(checkpointFile); previous != checkpointPath() {
+		if err := os.Rename(previous, checkpointPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	f, err := os.Open(checkpointPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		deputy := &Deputy{}
+		if err := json.Unmarshal(scanner.Bytes(), deputy); err != nil {
+			// The last line may be truncated if the run was killed mid-write.
+			warnf("skipping invalid checkpoint line: %v", err)
+			continue
+		}
+		resumedDeputies[deputy.ID] = deputy
+	}
+
+	infof("resuming with %d deputies already scraped", len(resumedDeputies))
+
+	return scanner.Err()
+}
+
+// writeCheckpoint appends the flushed deputies to the checkpoint, one JSON object per line.
+func writeCheckpoint(ctx context.Context, deputies []*Deputy) error {
+	f, err := os.OpenFile(checkpointPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for _, d := range deputies {
+		if resumedDeputies[d.ID] == d {
+			continue
+		}
+		if err := encoder.Encode(d); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func removeCheckpoint() {
+	if err := os.Remove(checkpointPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errorf("%v", err)
+	}
+}
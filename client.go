@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m2tx/gocrawler/collector"
+)
+
+var (
+	maxRedirects = 10
+
+	errTooManyRedirects = errors.New("error.too.many.redirects")
+
+	httpClient = &http.Client{
+		CheckRedirect: checkRedirect,
+	}
+)
+
+func redirectChain(req *http.Request, via []*http.Request) string {
+	var chain []string
+	for _, r := range via {
+		chain = append(chain, r.URL.String())
+	}
+
+	return strings.Join(append(chain, req.URL.String()), " -> ")
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	debugf("redirect %s", redirectChain(req, via))
+
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w after %d redirects: %s", errTooManyRedirects, len(via), redirectChain(req, via))
+	}
+
+	return nil
+}
+
+// contextClient binds every request made by a collector to ctx, since
+// collector.Visit does not take a context of its own.
+type contextClient struct {
+	ctx    context.Context
+	client collector.HTTPClient
+}
+
+func (c *contextClient) Do(req *http.Request) (*http.Response, error) {
+	if respectRobots {
+		if err := checkRobots(c.ctx, c.client, req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := waitRequestInterval(c.ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	return c.client.Do(req.WithContext(c.ctx))
+}
+
+var (
+	// requestInterval is the minimum time between two requests to the site, shared by every collector.
+	requestInterval time.Duration
+
+	// requestJitter adds a random delay of up to this much to each interval, so the
+	// requests do not arrive at a fixed pace.
+	requestJitter time.Duration
+
+	requestMutex sync.Mutex
+	nextRequest  time.Time
+)
+
+func waitRequestInterval(ctx context.Context) error {
+	requestMutex.Lock()
+	interval := requestInterval
+	if requestJitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(requestJitter)))
+	}
+	if interval <= 0 {
+		requestMutex.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	if nextRequest.Before(now) {
+		nextRequest = now
+	}
+	wait := nextRequest.Sub(now)
+	nextRequest = nextRequest.Add(interval)
+	requestMutex.Unlock()
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// raiseRequestInterval makes interval the minimum time between requests when it is
+// longer than the current one, reporting whether it was.
+func raiseRequestInterval(interval time.Duration) bool {
+	requestMutex.Lock()
+	defer requestMutex.Unlock()
+
+	if interval <= requestInterval {
+		return false
+	}
+	requestInterval = interval
+
+	return true
+}
+
+func newCollector(ctx context.Context) collector.Collector {
+	return collector.New(&contextClient{
+		ctx:    ctx,
+		client: httpClient,
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// permanentCommitteeType is the API code of the permanent committees among the Câmara organs.
+const permanentCommitteeType = "2"
+
+// withCommittees records the permanent committees each deputy sat on in the year.
+var withCommittees bool
+
+type Committee struct {
+	Acronym string `json:"acronym"`
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Chair   bool   `json:"chair,omitempty"`
+}
+
+type apiOrgan struct {
+	ID      int    `json:"id"`
+	OrganID int    `json:"idOrgao"`
+	Acronym string `json:"sigla"`
+	Name    string `json:"nome"`
+
+	MemberAcronym string `json:"siglaOrgao"`
+	MemberName    string `json:"nomeOrgao"`
+	Title         string `json:"titulo"`
+}
+
+// The permanent committees are listed once per run and shared by every worker.
+var (
+	committeesMutex  sync.Mutex
+	committeesLoaded bool
+	committeeIDs     map[int]bool
+	committeesErr    error
+)
+
+func permanentCommittees(ctx context.Context) (map[int]bool, error) {
+	committeesMutex.Lock()
+	defer committeesMutex.Unlock()
+
+	if !committeesLoaded {
+		committeeIDs, committeesErr = loadPermanentCommittees(ctx)
+		committeesLoaded = true
+	}
+
+	return committeeIDs, committeesErr
+}
+
+func loadPermanentCommittees(ctx context.Context) (map[int]bool, error) {
+	ids := map[int]bool{}
+	err := getAPIPages(ctx, fmt.Sprintf("%s/orgaos?codTipoOrgao=%s&itens=100", apiURL, permanentCommitteeType), func(data json.RawMessage) error {
+		var page []apiOrgan
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+
+		for _, o := range page {
+			ids[o.ID] = true
+		}
+
+		return nil
+	})
+
+	return ids, err
+}
+
+// fetchCommittees reads /deputados/{id}/orgaos of the API for the year, keeping the permanent committees.
+func fetchCommittees(ctx context.Context, deputy *Deputy) error {
+	permanent, err := permanentCommittees(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("dataInicio", fmt.Sprintf("%d-01-01", year))
+	query.Set("dataFim", fmt.Sprintf("%d-12-31", year))
+	query.Set("itens", "100")
+
+	deputy.Committees = nil
+	return getAPIPages(ctx, fmt.Sprintf("%s/deputados/%s/orgaos?%s", apiURL, deputy.ID, query.Encode()), func(data json.RawMessage) error {
+		var page []apiOrgan
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+
+		for _, o := range page {
+			if !permanent[o.OrganID] {
+				continue
+			}
+
+			title := strings.ToLower(o.Title)
+			deputy.Committees = append(deputy.Committees, Committee{
+				Acronym: o.MemberAcronym,
+				Name:    o.MemberName,
+				Role:    o.Title,
+				Chair:   strings.HasPrefix(title, "presidente"),
+			})
+		}
+
+		return nil
+	})
+}
+
+// committeeTotals sums the total of the deputies sitting on each committee.
+func committeeTotals(deputies []*Deputy) map[string]float64 {
+	totals := map[string]float64{}
+	for _, d := range deputies {
+		seen := map[string]bool{}
+		for _, c := range d.Committees {
+			if seen[c.Acronym] {
+				continue
+			}
+			seen[c.Acronym] = true
+			totals[c.Acronym] += d.Total
+		}
+	}
+
+	return totals
+}
+
+func writeCommitteeTotals() error {
+	bytes, err := encodeOutputJSON(committeeTotals(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("committee_total.json", bytes)
+}
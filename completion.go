@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// commandNames lists the subcommands in the order they are offered for completion.
+var commandNames = []string{"scrape", "report", "validate", "serve", "ceap", "senado", "alesp", "completion"}
+
+// politicalParties are the party codes used by the site, offered when completing -partido.
+var politicalParties = []string{
+	"AVANTE", "CIDADANIA", "MDB", "NOVO", "PCdoB", "PDT", "PL", "PODE", "PP", "PRD",
+	"PSB", "PSD", "PSDB", "PSOL", "PT", "PV", "REDE", "REPUBLICANOS", "SOLIDARIEDADE", "UNIÃO",
+}
+
+type completionFlag struct {
+	Name   string
+	Usage  string
+	IsBool bool
+}
+
+type completionData struct {
+	Commands []string
+	Flags    map[string][]completionFlag
+	States   []string
+	Parties  []string
+}
+
+var completionTemplates = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+// runCompletion prints the completion script for the shell given as argument.
+func runCompletion(ctx context.Context) error {
+	if len(commandArgs) != 1 {
+		return fmt.Errorf("usage: godeputy completion bash|zsh|fish")
+	}
+
+	text, ok := completionTemplates[commandArgs[0]]
+	if !ok {
+		return fmt.Errorf("unknown shell %q, expected bash, zsh or fish", commandArgs[0])
+	}
+
+	tmpl, err := template.New(commandArgs[0]).Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"quote": fishQuote,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(os.Stdout, newCompletionData())
+}
+
+func newCompletionData() completionData {
+	data := completionData{
+		Commands: commandNames,
+		Flags:    map[string][]completionFlag{},
+		States:   states,
+		Parties:  politicalParties,
+	}
+
+	for _, command := range commandNames {
+		if command == "completion" {
+			continue
+		}
+
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		registerFlags(fs)
+		registerCommandFlags(command, fs)
+		fs.VisitAll(func(f *flag.Flag) {
+			b, ok := f.Value.(interface{ IsBoolFlag() bool })
+			data.Flags[command] = append(data.Flags[command], completionFlag{
+				Name:   f.Name,
+				Usage:  f.Usage,
+				IsBool: ok && b.IsBoolFlag(),
+			})
+		})
+	}
+
+	return data
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
+
+const bashCompletion = `# bash completion for godeputy, load it with: source <(godeputy completion bash)
+_godeputy() {
+	local cur prev command flags
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+	case "$prev" in
+	-uf|--uf)
+		COMPREPLY=($(compgen -W "{{join .States " "}}" -- "$cur"))
+		return
+		;;
+	-partido|--partido)
+		COMPREPLY=($(compgen -W "{{join .Parties " "}}" -- "$cur"))
+		return
+		;;
+	esac
+
+	command=scrape
+	if [[ ${COMP_CWORD} -gt 1 && ${COMP_WORDS[1]} != -* ]]; then
+		command="${COMP_WORDS[1]}"
+	fi
+
+	case "$command" in
+	completion)
+		COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+		return
+		;;
+{{- range $command, $flags := .Flags}}
+	{{$command}})
+		flags="{{range $flags}}-{{.Name}} {{end}}"
+		;;
+{{- end}}
+	esac
+
+	if [[ ${COMP_CWORD} -eq 1 && $cur != -* ]]; then
+		COMPREPLY=($(compgen -W "{{join .Commands " "}}" -- "$cur"))
+		return
+	fi
+
+	COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+}
+complete -o default -F _godeputy godeputy
+`
+
+const zshCompletion = `#compdef godeputy
+# zsh completion for godeputy, load it with: source <(godeputy completion zsh)
+autoload -U +X bashcompinit && bashcompinit
+` + bashCompletion
+
+const fishCompletion = `# fish completion for godeputy, load it with: godeputy completion fish | source
+complete -c godeputy -f
+complete -c godeputy -n __fish_use_subcommand -a '{{join .Commands " "}}'
+complete -c godeputy -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'
+{{- range $command, $flags := .Flags}}
+{{- range $flags}}
+complete -c godeputy -n '{{if eq $command "scrape"}}not __fish_seen_subcommand_from {{range $.Commands}}{{if ne . $command}}{{.}} {{end}}{{end}}{{else}}__fish_seen_subcommand_from {{$command}}{{end}}' -o {{.Name}}{{if not .IsBool}} -r{{end}} -d {{quote .Usage}}
+{{- end}}
+{{- end}}
+complete -c godeputy -o uf -x -a '{{join .States " "}}'
+complete -c godeputy -o partido -x -a '{{join .Parties " "}}'
+`
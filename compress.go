@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// compressOutput gzips the JSON and CSV files written to outputDir, adding .gz to their
+// names. Full-year runs with -documents produce files of hundreds of megabytes.
+var compressOutput bool
+
+var compressedExtensions = map[string]bool{
+	".json": true,
+	".csv":  true,
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	w := gzip.NewWriter(&buffer)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// readOutputFile reads a file written by a previous run, falling back to name.gz when
+// the run compressed it, so readers never need to know about -compress.
+func readOutputFile(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) && !strings.HasSuffix(name, ".gz") {
+		if compressed, gzErr := os.ReadFile(name + ".gz"); gzErr == nil {
+			name, data, err = name+".gz", compressed, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(name, ".gz") {
+		return data, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "godeputy.yaml"
+
+var configFile = defaultConfigFile
+
+// setFlags returns the names of the flags given on the command line.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	return set
+}
+
+// configValue turns a YAML value into the text the matching flag parses;
+// lists become comma separated values.
+func configValue(v any) string {
+	if list, ok := v.([]any); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(v)
+}
+
+// loadConfigFile sets every flag named in the YAML file that was not given on the
+// command line. A missing file is only an error when it was asked for explicitly.
+func loadConfigFile(fset *flag.FlagSet, name string, required bool) error {
+	bytes, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	config := map[string]any{}
+	if err := yaml.Unmarshal(bytes, &config); err != nil {
+		return fmt.Errorf("error.config.file: %s: %v", name, err)
+	}
+
+	set := setFlags(fset)
+	for key, value := range config {
+		if key == "config" {
+			continue
+		}
+		if fset.Lookup(key) == nil {
+			return fmt.Errorf("error.config.file: %s: unknown option %q", name, key)
+		}
+		if set[key] || value == nil {
+			continue
+		}
+		if err := fset.Set(key, configValue(value)); err != nil {
+			return fmt.Errorf("error.config.file: %s: %s: %v", name, key, err)
+		}
+	}
+
+	return nil
+}
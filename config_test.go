@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "godeputy.yaml")
+	require.NoError(t, os.WriteFile(name, []byte("ano: 2023\nlegislatura: 56\nmes: [1, 2]\n"), 0644))
+
+	var legislature, year int
+	var months string
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.IntVar(&legislature, "legislatura", 57, "")
+	fs.IntVar(&year, "ano", 2024, "")
+	fs.StringVar(&months, "mes", "", "")
+	require.NoError(t, fs.Parse([]string{"-legislatura", "55"}))
+
+	require.NoError(t, loadConfigFile(fs, name, true))
+
+	assert.Equal(t, 55, legislature)
+	assert.Equal(t, 2023, year)
+	assert.Equal(t, "1,2", months)
+
+	assert.NoError(t, loadConfigFile(fs, filepath.Join(t.TempDir(), "missing.yaml"), false))
+	assert.Error(t, loadConfigFile(fs, filepath.Join(t.TempDir(), "missing.yaml"), true))
+}
+
+func TestLoadEnv(t *testing.T) {
+	var year int
+	var baseURL string
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.IntVar(&year, "ano", 2024, "")
+	fs.StringVar(&baseURL, "base-url", "", "")
+	require.NoError(t, fs.Parse([]string{"-base-url", "http://flag"}))
+
+	require.NoError(t, loadEnv(fs, []string{
+		"GODEPUTY_YEAR=2022",
+		"GODEPUTY_BASE_URL=http://env",
+		"HOME=/root",
+	}))
+
+	assert.Equal(t, 2022, year)
+	assert.Equal(t, "http://flag", baseURL)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
+var deputyCSVHeader = []string{
+	"id",
+	"name",
+	"politicalParty",
+	"state",
+	"salary",
+	"officeBudget",
+	"parliamentaryQuota",
+	"airTickets",
+	"travelExpenses",
+	"housingAllowance",
+	"functionalApartment",
+	"total",
+	"sourceURL",
+}
+
+// deputyCSVRecords returns the header and rows of deputies.csv, without the columns of
+// the figures -fields did not select.
+func deputyCSVRecords(deputies []*Deputy) ([]string, [][]string) {
+	header, keep := selectedDeputyCSVColumns()
+
+	records := make([][]string, 0, len(deputies))
+	for _, d := range deputies {
+		full := deputyCSVRecord(d)
+		record := make([]string, 0, len(keep))
+		for _, i := range keep {
+			record = append(record, full[i])
+		}
+		records = append(records, record)
+	}
+
+	return header, records
+}
+
+// selectedDeputyCSVColumns returns the columns of deputyCSVHeader that are written and
+// their positions in deputyCSVRecord.
+func selectedDeputyCSVColumns() (header []string, keep []int) {
+	omitted := omittedFigures()
+	for i, column := range deputyCSVHeader {
+		if !omitted[column] {
+			header = append(header, column)
+			keep = append(keep, i)
+		}
+	}
+
+	return header, keep
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, bitSize)
+}
+
+func deputyCSVRecord(d *Deputy) []string {
+	return []string{
+		d.ID,
+		d.Name,
+		d.PoliticalParty,
+		d.State,
+		formatCSVFloat(d.Salary),
+		formatCSVFloat(d.OfficeBudget),
+		formatCSVFloat(d.ParliamentaryQuota),
+		formatCSVFloat(d.AirTickets),
+		formatCSVFloat(d.TravelExpenses),
+		formatCSVFloat(d.HousingAllowance),
+		strconv.FormatBool(d.FunctionalApartment),
+		formatCSVFloat(d.Total),
+		d.SourceURL,
+	}
+}
+
+var costDetailCSVHeader = append(expenseDeputyCSVHeader[:len(expenseDeputyCSVHeader):len(expenseDeputyCSVHeader)],
+	"category",
+	"value",
+)
+
+// expenseDeputyCSVHeader leads cost_details.csv and expenses_long.csv with the deputy of
+// each expense, in the order of expenseDeputyCSVRecord.
+var expenseDeputyCSVHeader = []string{
+	"deputy_id",
+	"deputy_name",
+	"party",
+	"state",
+}
+
+func expenseDeputyCSVRecord(d *Deputy, values ...string) []string {
+	return append([]string{d.ID, d.Name, d.PoliticalParty, d.State}, values...)
+}
+
+// costDetailCSVRecords flattens the quota details into one row per deputy and category.
+func costDetailCSVRecords(deputies []*Deputy) [][]string {
+	var records [][]string
+	for _, d := range deputies {
+		for _, detail := range d.ParliamentaryQuotaDetails {
+			records = append(records, expenseDeputyCSVRecord(d,
+				detail.Description,
+				formatCSVFloat(detail.Value),
+			))
+		}
+	}
+
+	return records
+}
+
+func encodeCSV(header []string, records [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	w := csv.NewWriter(&buffer)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeDeputiesCSV writes one row per deputy with the same columns posted to -sheet-url as csv.
+func writeDeputiesCSV() error {
+	bytes, err := encodeCSV(deputyCSVRecords(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("deputies.csv", bytes)
+}
+
+func writeCostDetailsCSV() error {
+	bytes, err := encodeCSV(costDetailCSVHeader, costDetailCSVRecords(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("cost_details.csv", bytes)
+}
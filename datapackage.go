@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dataPackage is the Frictionless Data descriptor of the CSV files of a run, see
+// https://specs.frictionlessdata.io/tabular-data-package/.
+type dataPackage struct {
+	Profile   string         `json:"profile"`
+	Name      string         `json:"name"`
+	Title     string         `json:"title"`
+	Created   time.Time      `json:"created"`
+	Sources   []dataSource   `json:"sources"`
+	Resources []dataResource `json:"resources"`
+}
+
+type dataSource struct {
+	Title string `json:"title"`
+	Path  string `json:"path"`
+}
+
+type dataResource struct {
+	Profile     string      `json:"profile"`
+	Name        string      `json:"name"`
+	Path        string      `json:"path"`
+	Format      string      `json:"format"`
+	MediaType   string      `json:"mediatype"`
+	Encoding    string      `json:"encoding"`
+	Compression string      `json:"compression,omitempty"`
+	Schema      tableSchema `json:"schema"`
+}
+
+type tableSchema struct {
+	Fields      []tableField      `json:"fields"`
+	PrimaryKey  []string          `json:"primaryKey,omitempty"`
+	ForeignKeys []tableForeignKey `json:"foreignKeys,omitempty"`
+}
+
+type tableField struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+}
+
+type tableForeignKey struct {
+	Fields    []string `json:"fields"`
+	Reference struct {
+		Resource string   `json:"resource"`
+		Fields   []string `json:"fields"`
+	} `json:"reference"`
+}
+
+// csvFieldTypes are the table schema types of the CSV columns that are not strings.
+var csvFieldTypes = map[string]string{
+	"salary":              "number",
+	"officeBudget":        "number",
+	"parliamentaryQuota":  "number",
+	"airTickets":          "number",
+	"travelExpenses":      "number",
+	"housingAllowance":    "number",
+	"functionalApartment": "boolean",
+	"total":               "number",
+	"value":               "number",
+	"year":                "integer",
+	"month":               "integer",
+}
+
+func tableFields(header []string) []tableField {
+	fields := make([]tableField, 0, len(header))
+	for _, name := range header {
+		field := tableField{Name: name, Type: "string"}
+		if t, ok := csvFieldTypes[name]; ok {
+			field.Type = t
+		}
+		if name == "sourceURL" {
+			field.Format = "uri"
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func csvResource(name, path string, header []string) dataResource {
+	resource := dataResource{
+		Profile:   "tabular-data-resource",
+		Name:      name,
+		Path:      outputName(path),
+		Format:    "csv",
+		MediaType: "text/csv",
+		Encoding:  "utf-8",
+		Schema:    tableSchema{Fields: tableFields(header)},
+	}
+	if strings.HasSuffix(resource.Path, ".gz") {
+		resource.Compression = "gz"
+	}
+
+	return resource
+}
+
+// newDataPackage describes deputies.csv and cost_details.csv, and expenses_long.csv when
+// it is written, whose rows refer to the deputies by ID. The combined outputs of several
+// years or legislatures list a deputy once for each, so the ID is only declared as the
+// key of deputies.csv when it is unique.
+func newDataPackage(metadata RunMetadata, deputies []*Deputy) dataPackage {
+	header, _ := selectedDeputyCSVColumns()
+	deputiesResource := csvResource("deputies", "deputies.csv", header)
+	details := csvResource("cost-details", "cost_details.csv", costDetailCSVHeader)
+	var long *dataResource
+	if wantFormat("long") {
+		resource := csvResource("expenses-long", "expenses_long.csv", longCSVHeader)
+		long = &resource
+	}
+
+	if uniqueDeputyIDs(deputies) {
+		deputiesResource.Schema.PrimaryKey = []string{"id"}
+
+		foreignKey := tableForeignKey{Fields: []string{"deputy_id"}}
+		foreignKey.Reference.Resource = deputiesResource.Name
+		foreignKey.Reference.Fields = []string{"id"}
+		details.Schema.ForeignKeys = []tableForeignKey{foreignKey}
+		if long != nil {
+			long.Schema.ForeignKeys = []tableForeignKey{foreignKey}
+		}
+	}
+
+	resources := []dataResource{deputiesResource, details}
+	if long != nil {
+		resources = append(resources, *long)
+	}
+
+	return dataPackage{
+		Profile: "tabular-data-package",
+		Name:    fmt.Sprintf("godeputy-%d-%d", metadata.Legislature, metadata.Year),
+		Title:   fmt.Sprintf("%s %d/%d", translate("report.title"), metadata.Legislature, metadata.Year),
+		Created: metadata.FinishedAt,
+		Sources: []dataSource{{
+			Title: "Câmara dos Deputados",
+			Path:  baseURL,
+		}},
+		Resources: resources,
+	}
+}
+
+func uniqueDeputyIDs(deputies []*Deputy) bool {
+	seen := map[string]bool{}
+	for _, d := range deputies {
+		if seen[d.ID] {
+			return false
+		}
+		seen[d.ID] = true
+	}
+
+	return true
+}
+
+// writeDataPackage writes datapackage.json next to the CSV files it describes.
+func writeDataPackage() error {
+	bytes, err := json.MarshalIndent(newDataPackage(runMetadata(), deputiesArray), "", " ")
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("datapackage.json", bytes)
+}
@@ -0,0 +1,43 @@
+package main
+
+// demographicAttributes are the profile attributes the spending is grouped by in
+// demographics.json, keyed by the name used in that file.
+var demographicAttributes = map[string]func(p *DeputyProfile) string{
+	"gender":     func(p *DeputyProfile) string { return p.Gender },
+	"education":  func(p *DeputyProfile) string { return p.Education },
+	"birthState": func(p *DeputyProfile) string { return p.BirthState },
+}
+
+// demographicStats groups the deputies with a profile by each demographic attribute and
+// computes the count, total, mean and median spending of each group, the same way as the
+// party stats. An attribute the profile leaves empty is grouped as "unknown".
+func demographicStats(deputies []*Deputy) map[string]map[string]PartyStats {
+	stats := map[string]map[string]PartyStats{}
+	for name, attribute := range demographicAttributes {
+		groups := map[string][]*Deputy{}
+		for _, d := range deputies {
+			if d.Profile == nil {
+				continue
+			}
+
+			value := attribute(d.Profile)
+			if value == "" {
+				value = "unknown"
+			}
+			groups[value] = append(groups[value], d)
+		}
+
+		stats[name] = partyStatsMap(groups)
+	}
+
+	return stats
+}
+
+func writeDemographicStats() error {
+	bytes, err := encodeOutputJSON(demographicStats(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("demographics.json", bytes)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+// withDocuments keeps the expense rows behind each quota category as its Documents.
+var withDocuments bool
+
+var (
+	// quotaCategoryLinkQuery matches the link of a quota table row to the expenses of its category.
+	quotaCategoryLinkQuery selector.QueryString = "a"
+
+	// quotaDocumentQuery matches the expense rows of a category page: date, supplier, CNPJ
+	// or CPF, document number and value, with the receipt linked from any of the cells.
+	quotaDocumentQuery selector.QueryString = "table#js-documentos tbody tr"
+
+	// quotaDocumentPageQuery matches the pagination links of a category page.
+	quotaDocumentPageQuery selector.QueryString = "nav.pagination a"
+)
+
+// quotaExpense builds the figures of a single quota expense row of the APIs and open data
+// dumps, counting it in AirTickets too when its category pays for air tickets.
+func quotaExpense(category string, value float64, document CostDetail) *Deputy {
+	expense := &Deputy{ParliamentaryQuota: value}
+	if isAirTicket(category) {
+		expense.AirTickets = value
+	}
+	if withDetails {
+		expense.ParliamentaryQuotaDetails = []CostDetail{expenseDetail(category, value, document)}
+	}
+
+	return expense
+}
+
+// expenseDetail builds the category detail of a single expense row, keeping the row
+// itself, with its supplier and receipt link, as the only document of the category when
+// -documents is set.
+func expenseDetail(category string, value float64, document CostDetail) CostDetail {
+	detail := CostDetail{Description: category, Value: value}
+	if withDocuments {
+		document.Description, document.Value = category, value
+		detail.Documents = []CostDetail{document}
+	}
+
+	return detail
+}
+
+// visitQuotaDocuments follows the link of each category of the quota table on page to
+// the expense rows behind it, keeping them as the Documents of the category collected
+// from that row.
+func visitQuotaDocuments(ctx context.Context, deputy *Deputy, pageURL string, page *html.Node) error {
+	if page == nil {
+		return nil
+	}
+
+	attrHref := selector.Attribute("href")
+	cellQuery := selector.QueryString("td")
+	for _, row := range quotaRowQuery.Select(page) {
+		cells := cellQuery.Select(row)
+		if len(cells) < 2 {
+			continue
+		}
+
+		links := quotaCategoryLinkQuery.Select(cells[0])
+		if len(links) == 0 {
+			continue
+		}
+
+		link := resolveLink(pageURL, attrHref.Val(links[0]))
+		if link == "" {
+			continue
+		}
+
+		category := nodeText(cells[0])
+		documents, err := visitDocumentPages(ctx, deputy, category, link)
+		if err != nil {
+			return err
+		}
+
+		for i := range deputy.ParliamentaryQuotaDetails {
+			if detail := &deputy.ParliamentaryQuotaDetails[i]; detail.Description == category {
+				detail.Documents = append(detail.Documents, documents...)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// visitDocumentPages reads the expense rows of a category page and of the pages following it.
+func visitDocumentPages(ctx context.Context, deputy *Deputy, category, link string) ([]CostDetail, error) {
+	var documents []CostDetail
+
+	seen := map[string]bool{}
+	for pages := 0; link != "" && !seen[link]; pages++ {
+		if pages == maxQuotaPages {
+			warnf("expenses of %q of deputy %s have more than %d pages, the rest is ignored", category, deputy.ID, maxQuotaPages)
+			break
+		}
+		seen[link] = true
+
+		var page *html.Node
+		c := newCollector(ctx)
+
+		c.OnRequest(func(req *http.Request) error {
+			debugf("visit %s", req.URL)
+
+			return nil
+		})
+
+		c.OnNode("html", func(req *http.Request, resp *http.Response, node *html.Node) error {
+			page = node
+
+			return nil
+		})
+
+		pageURL := link
+		c.OnNode(quotaDocumentQuery, func(req *http.Request, resp *http.Response, node *html.Node) error {
+			document, err := extractDocument(pageURL, category, node)
+			if errors.Is(err, errValueMissing) {
+				markMissing(deputy, "parliamentaryQuotaDetails")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			documents = append(documents, document)
+
+			return nil
+		})
+
+		if err := c.Visit(link); err != nil {
+			return nil, visitError(ctx, deputy, err)
+		}
+
+		link = nextPage(quotaDocumentPageQuery, link, page)
+	}
+
+	return documents, nil
+}
+
+// extractDocument reads an expense row of a category page.
+func extractDocument(pageURL, category string, row *html.Node) (CostDetail, error) {
+	cellQuery := selector.QueryString("td")
+	cells := cellQuery.Select(row)
+	if len(cells) < 5 {
+		return CostDetail{}, fmt.Errorf("error.document.row: %d cells", len(cells))
+	}
+
+	value, err := parseValue(nodeText(cells[4]))
+	if err != nil {
+		return CostDetail{}, err
+	}
+
+	document := CostDetail{
+		Description:    category,
+		Value:          value,
+		Date:           nodeText(cells[0]),
+		SupplierName:   nodeText(cells[1]),
+		SupplierCNPJ:   nodeText(cells[2]),
+		DocumentNumber: nodeText(cells[3]),
+	}
+
+	linkQuery := selector.QueryString("a")
+	if links := linkQuery.Select(row); len(links) > 0 {
+		document.DocumentURL = resolveLink(pageURL, selector.Attribute("href").Val(links[0]))
+	}
+
+	return document, nil
+}
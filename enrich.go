@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// withProfile fetches the profile of every deputy from the Dados Abertos API.
+var withProfile bool
+
+// deputyEnricher adds data that is not on the cost page, such as the profile, to a deputy
+// once its costs are collected. Field is marked missing when Enrich fails.
+type deputyEnricher struct {
+	Field   string
+	Enabled *bool
+	Enrich  func(ctx context.Context, deputy *Deputy) error
+}
+
+var deputyEnrichers = []deputyEnricher{
+	{Field: "officeBudgetDetails", Enabled: &withStaff, Enrich: visitStaffDetails},
+	{Field: "profile", Enabled: &withProfile, Enrich: fetchDeputyProfile},
+	{Field: "attendance", Enabled: &withAttendance, Enrich: visitAttendance},
+	{Field: "votes", Enabled: &withVotes, Enrich: attachVotes},
+	{Field: "mandate", Enabled: &withMandate, Enrich: fetchMandate},
+	{Field: "partyHistory", Enabled: &withPartyHistory, Enrich: fetchPartyHistory},
+	{Field: "committees", Enabled: &withCommittees, Enrich: fetchCommittees},
+	{Field: "productivity", Enabled: &withProductivity, Enrich: fetchProductivity},
+	{Field: "campaign", Enabled: &withCampaign, Enrich: fetchCampaign},
+}
+
+// enrichDeputy runs the enrichers enabled by the command line flags; a failure only
+// loses the data of that enricher, not the deputy.
+func enrichDeputy(ctx context.Context, deputy *Deputy) {
+	for _, e := range deputyEnrichers {
+		if !*e.Enabled {
+			continue
+		}
+
+		if err := e.Enrich(ctx, deputy); err != nil {
+			warnf("%s of deputy %s: %v", e.Field, deputy.ID, err)
+			markMissing(deputy, e.Field)
+		}
+	}
+}
+
+type DeputyProfile struct {
+	CivilName  string `json:"civilName,omitempty"`
+	BirthDate  string `json:"birthDate,omitempty"`
+	CPF        string `json:"cpf,omitempty"`
+	Gender     string `json:"gender,omitempty"`
+	Education  string `json:"education,omitempty"`
+	BirthState string `json:"birthState,omitempty"`
+	BirthCity  string `json:"birthCity,omitempty"`
+	PhotoURL   string `json:"photoURL,omitempty"`
+	Email      string `json:"email,omitempty"`
+	Cabinet    string `json:"cabinet,omitempty"`
+	Building   string `json:"building,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+type apiDeputyProfile struct {
+	CivilName  string `json:"nomeCivil"`
+	BirthDate  string `json:"dataNascimento"`
+	CPF        string `json:"cpf"`
+	Gender     string `json:"sexo"`
+	Education  string `json:"escolaridade"`
+	BirthState string `json:"ufNascimento"`
+	BirthCity  string `json:"municipioNascimento"`
+	LastStatus struct {
+		PhotoURL string `json:"urlFoto"`
+		Email    string `json:"email"`
+		Cabinet  struct {
+			Name     string `json:"nome"`
+			Building string `json:"predio"`
+			Phone    string `json:"telefone"`
+			Email    string `json:"email"`
+		} `json:"gabinete"`
+	} `json:"ultimoStatus"`
+}
+
+// fetchDeputyProfile reads /deputados/{id} of the API into deputy.Profile.
+func fetchDeputyProfile(ctx context.Context, deputy *Deputy) error {
+	url := fmt.Sprintf("%s/deputados/%s", apiURL, deputy.ID)
+
+	var profile apiDeputyProfile
+	err := getAPIPages(ctx, url, func(data json.RawMessage) error {
+		return json.Unmarshal(data, &profile)
+	})
+	if err != nil {
+		return err
+	}
+
+	status := profile.LastStatus
+	email := status.Email
+	if email == "" {
+		email = status.Cabinet.Email
+	}
+
+	deputy.Profile = &DeputyProfile{
+		CivilName:  profile.CivilName,
+		BirthDate:  profile.BirthDate,
+		CPF:        profile.CPF,
+		Gender:     profile.Gender,
+		Education:  profile.Education,
+		BirthState: profile.BirthState,
+		BirthCity:  profile.BirthCity,
+		PhotoURL:   status.PhotoURL,
+		Email:      email,
+		Cabinet:    status.Cabinet.Name,
+		Building:   status.Cabinet.Building,
+		Phone:      status.Cabinet.Phone,
+	}
+
+	return nil
+}
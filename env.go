@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const envPrefix = "GODEPUTY_"
+
+// envAliases maps English variable names to the flags they set, next to the
+// GODEPUTY_<FLAG_NAME> form every flag accepts.
+var envAliases = map[string]string{
+	"LEGISLATURE": "legislatura",
+	"YEAR":        "ano",
+	"MONTH":       "mes",
+	"OUTPUT_DIR":  "out",
+	"WORKERS":     "workers",
+}
+
+func envFlagName(fs *flag.FlagSet, key string) string {
+	if name, ok := envAliases[key]; ok && fs.Lookup(name) != nil {
+		return name
+	}
+
+	name := strings.ReplaceAll(strings.ToLower(key), "_", "-")
+	if fs.Lookup(name) != nil {
+		return name
+	}
+
+	return ""
+}
+
+// loadEnv sets the flags named by GODEPUTY_* variables that were not given on the command line.
+func loadEnv(fs *flag.FlagSet, environ []string) error {
+	set := setFlags(fs)
+
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, envPrefix) {
+			continue
+		}
+
+		name := envFlagName(fs, strings.TrimPrefix(key, envPrefix))
+		if name == "" {
+			warnf("ignoring unknown variable %s", key)
+			continue
+		}
+		if set[name] {
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("error.env: %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func loadEnvironment(fs *flag.FlagSet) error {
+	return loadEnv(fs, os.Environ())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// outputSchemaVersion is bumped on every breaking change to the Deputy model or to the
+// layout of the JSON outputs. Version 1 files are the bare values, without the envelope.
+const outputSchemaVersion = "2"
+
+// outputEnvelope wraps every JSON output, so consumers can tell which version of the
+// model they are reading before decoding the data.
+type outputEnvelope struct {
+	SchemaVersion string          `json:"schemaVersion"`
+	GeneratedAt   time.Time       `json:"generatedAt"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// encodeOutputJSON indents v inside the envelope of the current schema version.
+func encodeOutputJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(outputEnvelope{
+		SchemaVersion: outputSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Data:          data,
+	}, "", " ")
+}
+
+// decodeOutputJSON reads the data of a JSON output into v, accepting the bare values
+// written before the envelope was introduced.
+func decodeOutputJSON(data []byte, v any) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return json.Unmarshal(data, v)
+	}
+
+	var envelope outputEnvelope
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return err
+	}
+
+	if envelope.SchemaVersion == "" || envelope.Data == nil {
+		// an object that is not an envelope, as the version 1 political_party.json
+		return json.Unmarshal(data, v)
+	}
+
+	if envelope.SchemaVersion != outputSchemaVersion {
+		return fmt.Errorf("unsupported schema version %q, expected %s", envelope.SchemaVersion, outputSchemaVersion)
+	}
+
+	return json.Unmarshal(envelope.Data, v)
+}
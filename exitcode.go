@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Exit codes of the godeputy process, so wrappers can tell a partial run from a failed one.
+const (
+	exitSuccess = 0
+	exitFatal   = 1
+	exitUsage   = 2
+	exitPartial = 3
+)
+
+// failedDeputies counts the deputies of the current scrape whose details could not be collected.
+var failedDeputies atomic.Int64
+
+// partialFailureError reports a run that wrote its outputs but missed some deputies.
+type partialFailureError struct {
+	Failed int
+}
+
+func (e *partialFailureError) Error() string {
+	return fmt.Sprintf("error.partial.failure: %d deputies failed to scrape", e.Failed)
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitSuccess
+	}
+
+	var partial *partialFailureError
+	if errors.As(err, &partial) {
+		return exitPartial
+	}
+
+	return exitFatal
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+type deputyExtractor struct {
+	Name    string
+	Query   selector.QueryString
+	Extract func(ctx context.Context, deputy *Deputy, node *html.Node) error
+}
+
+var (
+	// fields restricts the extractors registered for each deputy page; empty means all of them.
+	fields map[string]bool
+
+	errValueMissing = errors.New("error.value.missing")
+
+	// placeholderRegex matches the text the site shows instead of a value that is not available yet.
+	placeholderRegex = regexp.MustCompile(`(?i)(^|[\s:])(—|–|-|n/d|não disponível|não informado)\s*$`)
+
+	// parliamentaryQuotaSelectors are tried in order until one matches the quota total.
+	parliamentaryQuotaSelectors = []selector.QueryString{
+		"div.gastos__resumo div.card-body section p.gastos__resumo-texto--destaque span",
+		"div.gastos__resumo section p.gastos__resumo-texto--destaque span",
+		"section#cota p.gastos__resumo-texto--destaque span",
+	}
+
+	// quotaRowQuery matches the rows of the quota table, one per expense category.
+	quotaRowQuery selector.QueryString = "section#cota table#js-tipo-despesa.js-chart--pie tbody tr"
+
+	deputyExtractors = []deputyExtractor{
+		{Name: "office", Query: "section#verba div.container div.gastos__resumo p.gastos__resumo-texto--destaque", Extract: extractOfficeBudget},
+		{Name: "salary", Query: "div.remuneracao-viagens div#remuneracao p.remuneracao-viagens__desc", Extract: extractSalary},
+		{Name: "details", Query: quotaRowQuery, Extract: extractQuotaDetail},
+		{Name: "tickets", Query: quotaRowQuery, Extract: extractAirTickets},
+		{Name: "quota", Query: "html", Extract: extractParliamentaryQuota},
+		{Name: "travel", Query: "div.remuneracao-viagens div#viagens p.remuneracao-viagens__desc", Extract: extractTravelExpense},
+		{Name: "housing", Query: "div.remuneracao-viagens div#moradia p.remuneracao-viagens__desc", Extract: extractHousing},
+	}
+)
+
+// activeExtractors returns the deputy extractors enabled by the command line flags.
+func activeExtractors() []deputyExtractor {
+	var extractors []deputyExtractor
+	for _, e := range deputyExtractors {
+		if e.Name == "details" && !withDetails {
+			continue
+		}
+		if len(fields) > 0 && !fields[e.Name] {
+			continue
+		}
+		extractors = append(extractors, e)
+	}
+
+	return extractors
+}
+
+// omittedFigures are the figures -fields did not select, left out of the JSON and CSV
+// outputs rather than written as zeros.
+func omittedFigures() map[string]bool {
+	omitted := map[string]bool{}
+	if len(fields) == 0 {
+		return omitted
+	}
+
+	for _, f := range sourceFields {
+		if !fields[f.Extractor] {
+			omitted[f.Field] = true
+		}
+	}
+
+	return omitted
+}
+
+// collectedFields lists the names of the active extractors, as accepted by -fields.
+func collectedFields() []string {
+	var names []string
+	for _, e := range activeExtractors() {
+		names = append(names, e.Name)
+	}
+
+	return names
+}
+
+func parseFields(v string) (map[string]bool, error) {
+	known := map[string]bool{}
+	for _, e := range deputyExtractors {
+		known[e.Name] = true
+	}
+
+	selected := map[string]bool{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		selected[name] = true
+	}
+
+	return selected, nil
+}
+
+// extractDeputyDetails runs every active deputy extractor over an already parsed page,
+// the same way the collector does for a visited one.
+func extractDeputyDetails(ctx context.Context, doc *html.Node, deputy *Deputy) error {
+	for _, e := range activeExtractors() {
+		for _, node := range e.Query.Select(doc) {
+			if err := e.Extract(ctx, deputy, node); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isPlaceholder(data string) bool {
+	return strings.TrimSpace(data) == "" || placeholderRegex.MatchString(data)
+}
+
+func parseReal(data string) (float64, error) {
+	strs := realRegex.FindStringSubmatch(data)
+	if len(strs) == 0 {
+		if isPlaceholder(data) {
+			return 0, errValueMissing
+		}
+		return 0, fmt.Errorf("error.real.not.found: %q", data)
+	}
+
+	return parseMoney(strs[0])
+}
+
+func parseValue(data string) (float64, error) {
+	if isPlaceholder(data) {
+		return 0, errValueMissing
+	}
+
+	return parseMoney(data)
+}
+
+// markMissing records that field was shown as a placeholder, so its zero value
+// is not mistaken for a genuine zero.
+func markMissing(deputy *Deputy, field string) {
+	for _, f := range deputy.MissingFields {
+		if f == field {
+			return
+		}
+	}
+
+	deputy.MissingFields = append(deputy.MissingFields, field)
+}
+
+func extractOfficeBudget(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	officeBudget, err := parseReal(node.FirstChild.Data)
+	if errors.Is(err, errValueMissing) {
+		markMissing(deputy, "officeBudget")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	deputy.OfficeBudget = officeBudget
+
+	return nil
+}
+
+func extractSalary(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	salary, err := parseReal(node.FirstChild.Data)
+	if errors.Is(err, errValueMissing) {
+		markMissing(deputy, "salary")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	deputy.Salary = salary
+
+	return nil
+}
+
+// extractQuotaDetail runs once per row of the quota table, so it checks ctx to
+// stop parsing a large table once the visit has been cancelled.
+func extractQuotaDetail(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := selector.QueryString("td")
+	nodes := query.Select(node)
+
+	value, err := parseValue(nodes[1].FirstChild.Data)
+	if errors.Is(err, errValueMissing) {
+		markMissing(deputy, "parliamentaryQuotaDetails")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error.cost.details: %v", err)
+	}
+
+	costDetails := CostDetail{
+		Description: nodeText(nodes[0]),
+		Value:       value,
+	}
+	deputy.ParliamentaryQuotaDetails = append(deputy.ParliamentaryQuotaDetails, costDetails)
+
+	return nil
+}
+
+// isAirTicket tells whether a quota category pays for air tickets, which the chamber
+// books apart from the other expenses: "PASSAGEM AÉREA - SIGEPA", "PASSAGEM AÉREA - RPA",
+// "PASSAGENS AÉREAS" or the Senado's "Passagens aéreas, aquáticas e terrestres nacionais".
+func isAirTicket(category string) bool {
+	category = strings.ToLower(category)
+
+	return strings.Contains(category, "passage") && (strings.Contains(category, "aérea") || strings.Contains(category, "aerea"))
+}
+
+// extractAirTickets runs once per row of the quota table, adding the air ticket
+// categories to AirTickets whether or not the quota details are collected.
+func extractAirTickets(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	query := selector.QueryString("td")
+	nodes := query.Select(node)
+	if len(nodes) < 2 || nodes[1].FirstChild == nil || !isAirTicket(nodeText(nodes[0])) {
+		return nil
+	}
+
+	value, err := parseValue(nodes[1].FirstChild.Data)
+	if errors.Is(err, errValueMissing) {
+		markMissing(deputy, "airTickets")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error.air.tickets: %v", err)
+	}
+
+	deputy.AirTickets += value
+
+	return nil
+}
+
+func extractParliamentaryQuota(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	for _, query := range parliamentaryQuotaSelectors {
+		nodes := query.Select(node)
+		if len(nodes) == 0 || nodes[0].FirstChild == nil {
+			continue
+		}
+
+		parliamentaryQuota, err := parseValue(nodes[0].FirstChild.Data)
+		if errors.Is(err, errValueMissing) {
+			markMissing(deputy, "parliamentaryQuota")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error.cost.total: %v", err)
+		}
+
+		deputy.ParliamentaryQuota = parliamentaryQuota
+
+		return nil
+	}
+
+	warnf("no parliamentary quota selector matched for deputy %s", deputy.ID)
+
+	return nil
+}
+
+// extractTravelExpense runs once per line of the official travel section, such as
+// "Diárias: R$ 1.234,56", adding it to the travel total and its breakdown.
+func extractTravelExpense(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	if node.FirstChild == nil {
+		return nil
+	}
+
+	data := node.FirstChild.Data
+	description, _, _ := strings.Cut(data, ":")
+
+	value, err := parseReal(data)
+	if errors.Is(err, errValueMissing) {
+		markMissing(deputy, "travelExpenses")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error.travel: %v", err)
+	}
+
+	deputy.TravelExpenses += value
+	if withDetails {
+		deputy.TravelExpensesDetails = append(deputy.TravelExpensesDetails, CostDetail{
+			Description: strings.TrimSpace(description),
+			Value:       value,
+		})
+	}
+
+	return nil
+}
+
+// extractHousing reads the housing lines of the page: "Auxílio-moradia: R$ 4.253,00"
+// or "Auxílio-moradia: Não recebe", and "Imóvel funcional: Faz uso" or "Não faz uso".
+func extractHousing(ctx context.Context, deputy *Deputy, node *html.Node) error {
+	if node.FirstChild == nil {
+		return nil
+	}
+
+	label, value, _ := strings.Cut(node.FirstChild.Data, ":")
+	label, status := strings.ToLower(label), strings.ToLower(strings.TrimSpace(value))
+
+	switch {
+	case strings.Contains(label, "funcional"):
+		if isPlaceholder(status) {
+			markMissing(deputy, "functionalApartment")
+			return nil
+		}
+		deputy.FunctionalApartment = !strings.HasPrefix(status, "não")
+	case strings.Contains(label, "moradia"):
+		if strings.HasPrefix(status, "não recebe") {
+			deputy.HousingAllowance = 0
+			return nil
+		}
+
+		allowance, err := parseReal(value)
+		if errors.Is(err, errValueMissing) {
+			markMissing(deputy, "housingAllowance")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error.housing: %v", err)
+		}
+
+		deputy.HousingAllowance = allowance
+	}
+
+	return nil
+}
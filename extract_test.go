@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func parseFixture(tb testing.TB, name string) *html.Node {
+	tb.Helper()
+
+	f, err := os.Open(name)
+	require.NoError(tb, err)
+	defer f.Close()
+
+	doc, err := html.Parse(f)
+	require.NoError(tb, err)
+
+	return doc
+}
+
+func TestExtractDeputyDetails(t *testing.T) {
+	doc := parseFixture(t, "testdata/deputy.html")
+
+	deputy := &Deputy{ID: "1"}
+	require.NoError(t, extractDeputyDetails(context.Background(), doc, deputy))
+
+	assert.Equal(t, 41650.92, deputy.Salary)
+	assert.Equal(t, 1338571.75, deputy.OfficeBudget)
+	assert.Equal(t, 245310.77, deputy.ParliamentaryQuota)
+	assert.Len(t, deputy.ParliamentaryQuotaDetails, 5)
+	assert.Equal(t, CostDetail{Description: "TELEFONIA", Value: 6000}, deputy.ParliamentaryQuotaDetails[4])
+	assert.Equal(t, 5050.4, deputy.TravelExpenses)
+	assert.Equal(t, []CostDetail{
+		{Description: "Passagens", Value: 3200},
+		{Description: "Diárias", Value: 1850.4},
+	}, deputy.TravelExpensesDetails)
+	assert.Equal(t, 4253.0, deputy.HousingAllowance)
+	assert.False(t, deputy.FunctionalApartment)
+	assert.Equal(t, 40210.45, deputy.AirTickets)
+}
+
+func TestFieldsOmitUnselectedFigures(t *testing.T) {
+	defer func() { fields = nil }()
+
+	var err error
+	fields, err = parseFields("quota,salary")
+	require.NoError(t, err)
+
+	deputy := &Deputy{ID: "1", Name: "Fulano", Salary: 41650.92, ParliamentaryQuota: 0, Total: 41650.92}
+
+	data, err := json.Marshal(deputy)
+	require.NoError(t, err)
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &encoded))
+	assert.Equal(t, 41650.92, encoded["salary"])
+	assert.Equal(t, 0.0, encoded["parliamentaryQuota"])
+	for _, key := range []string{"officeBudget", "airTickets", "travelExpenses", "housingAllowance"} {
+		assert.NotContains(t, encoded, key)
+	}
+
+	header, records := deputyCSVRecords([]*Deputy{deputy})
+	assert.Equal(t, []string{"id", "name", "politicalParty", "state", "salary", "parliamentaryQuota", "functionalApartment", "total", "sourceURL"}, header)
+	assert.Equal(t, []string{"1", "Fulano", "", "", "41650.92", "0.00", "false", "41650.92", ""}, records[0])
+
+	fields = nil
+	data, err = json.Marshal(deputy)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"officeBudget":0`)
+}
+
+func BenchmarkSetDeputyDetails(b *testing.B) {
+	ctx := context.Background()
+	doc := parseFixture(b, "testdata/deputy.html")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := extractDeputyDetails(ctx, doc, &Deputy{ID: "1"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestExtractPlaceholders(t *testing.T) {
+	ctx := context.Background()
+	deputy := &Deputy{ID: "1"}
+
+	node := &html.Node{FirstChild: &html.Node{Type: html.TextNode, Data: "Salário bruto: —"}}
+	require.NoError(t, extractSalary(ctx, deputy, node))
+
+	node = &html.Node{FirstChild: &html.Node{Type: html.TextNode, Data: "Não disponível"}}
+	require.NoError(t, extractOfficeBudget(ctx, deputy, node))
+
+	assert.Equal(t, []string{"salary", "officeBudget"}, deputy.MissingFields)
+
+	_, err := parseValue("R$ 0,00")
+	assert.NoError(t, err)
+}
+
+func TestVisitStaffDetails(t *testing.T) {
+	page, err := os.ReadFile("testdata/staff.html")
+	require.NoError(t, err)
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	baseURL = server.URL
+	defer func() {
+		baseURL = "https://www.camara.leg.br"
+	}()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, visitStaffDetails(context.Background(), deputy))
+
+	assert.Equal(t, "/deputados/204554/pessoal-gabinete?ano=2024", requested)
+	assert.Equal(t, []CostDetail{
+		{Description: "Maria da Silva (Secretário Parlamentar)", Value: 96421.3},
+		{Description: "João Souza (Chefe de Gabinete)", Value: 120800},
+	}, deputy.OfficeBudgetDetails)
+	assert.Equal(t, []string{"officeBudgetDetails"}, deputy.MissingFields)
+}
+
+func TestVisitAttendance(t *testing.T) {
+	page, err := os.ReadFile("testdata/attendance.html")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/deputados/204554/presenca-plenario/2024", r.URL.Path)
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	baseURL = server.URL
+	defer func() {
+		baseURL = "https://www.camara.leg.br"
+	}()
+
+	deputy := &Deputy{ID: "204554"}
+	require.NoError(t, visitAttendance(context.Background(), deputy))
+
+	assert.Equal(t, &Attendance{
+		SessionDays:         120,
+		Present:             110,
+		JustifiedAbsences:   7,
+		UnjustifiedAbsences: 3,
+		Rate:                110.0 / 120,
+	}, deputy.Attendance)
+}
+
+func TestCheckLayout(t *testing.T) {
+	page, err := os.ReadFile("testdata/deputy.html")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("deputado") == "2" {
+			w.Write([]byte("<html><body><p>novo layout</p></body></html>"))
+			return
+		}
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	baseURL, outputDir, checkLayout = server.URL, t.TempDir(), true
+	defer func() {
+		baseURL, checkLayout, outputFiles = "https://www.camara.leg.br", false, nil
+	}()
+
+	ctx := context.Background()
+
+	resetLayoutCheck()
+	require.NoError(t, visitHTMLDeputyDetails(ctx, &Deputy{ID: "1"}, 0))
+	require.NoError(t, visitHTMLDeputyDetails(ctx, &Deputy{ID: "2"}, 0))
+	assert.NoError(t, layoutReport())
+
+	resetLayoutCheck()
+	require.NoError(t, visitHTMLDeputyDetails(ctx, &Deputy{ID: "2"}, 0))
+
+	var layoutErr *layoutError
+	require.ErrorAs(t, layoutReport(), &layoutErr)
+	require.Len(t, layoutErr.Misses, len(deputyExtractors))
+	assert.Equal(t, "details", layoutErr.Misses[0].Field)
+	assert.FileExists(t, layoutErr.Misses[0].DumpFile)
+	assert.Empty(t, outputFiles)
+}
+
+func TestVisitQuotaPages(t *testing.T) {
+	page, err := os.ReadFile("testdata/deputy.html")
+	require.NoError(t, err)
+	first := strings.Replace(string(page), "</table>", `</table><nav class="pagination"><a rel="next" href="?pagina=2">2</a></nav>`, 1)
+
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("pagina"))
+		if r.URL.Query().Get("pagina") == "2" {
+			w.Write([]byte(`<html><body><section id="cota"><table id="js-tipo-despesa" class="js-chart--pie"><tbody>
+<tr><td>SERVIÇOS POSTAIS</td><td>1.500,00</td></tr>
+</tbody></table><nav class="pagination"><a rel="prev" href="?pagina=1">1</a></nav></section></body></html>`))
+			return
+		}
+		w.Write([]byte(first))
+	}))
+	defer server.Close()
+
+	baseURL = server.URL
+	defer func() {
+		baseURL = "https://www.camara.leg.br"
+	}()
+
+	deputy := &Deputy{ID: "1"}
+	require.NoError(t, visitHTMLDeputyDetails(context.Background(), deputy, 0))
+
+	assert.Equal(t, []string{"", "2"}, pages)
+	require.Len(t, deputy.ParliamentaryQuotaDetails, 6)
+	assert.Equal(t, CostDetail{Description: "SERVIÇOS POSTAIS", Value: 1500}, deputy.ParliamentaryQuotaDetails[5])
+	assert.Equal(t, 245310.77, deputy.ParliamentaryQuota)
+}
+
+func TestVisitQuotaDocuments(t *testing.T) {
+	page, err := os.ReadFile("testdata/deputy.html")
+	require.NoError(t, err)
+	first := strings.Replace(string(page), "<td>TELEFONIA</td>", `<td><a href="/cota/documentos?tipo=telefonia">TELEFONIA</a></td>`, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cota/documentos" {
+			w.Write([]byte(`<html><body><table id="js-documentos"><tbody>
+<tr><td>15/01/2024</td><td>TELEFÔNICA BRASIL S.A.</td><td>02.558.157/0001-62</td><td>123</td><td><a href="/recibos/1.pdf">4.000,00</a></td></tr>
+<tr><td>15/02/2024</td><td>CLARO S.A.</td><td>40.432.544/0001-47</td><td>456</td><td>2.000,00</td></tr>
+</tbody></table></body></html>`))
+			return
+		}
+		w.Write([]byte(first))
+	}))
+	defer server.Close()
+
+	baseURL, withDocuments = server.URL, true
+	defer func() {
+		baseURL, withDocuments = "https://www.camara.leg.br", false
+	}()
+
+	deputy := &Deputy{ID: "1"}
+	require.NoError(t, visitHTMLDeputyDetails(context.Background(), deputy, 0))
+
+	require.Len(t, deputy.ParliamentaryQuotaDetails, 5)
+	assert.Equal(t, []CostDetail{
+		{Description: "TELEFONIA", Value: 4000, Date: "15/01/2024", SupplierName: "TELEFÔNICA BRASIL S.A.", SupplierCNPJ: "02.558.157/0001-62", DocumentNumber: "123", DocumentURL: server.URL + "/recibos/1.pdf"},
+		{Description: "TELEFONIA", Value: 2000, Date: "15/02/2024", SupplierName: "CLARO S.A.", SupplierCNPJ: "40.432.544/0001-47", DocumentNumber: "456"},
+	}, deputy.ParliamentaryQuotaDetails[4].Documents)
+	assert.Empty(t, deputy.ParliamentaryQuotaDetails[0].Documents)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ufFilter restricts the scrape to deputies of these states.
+	ufFilter []string
+
+	// partyFilter restricts the scrape to deputies of these political parties.
+	partyFilter []string
+
+	// deputyIDFilter and deputyNameFilter restrict the scrape to deputies with these IDs or matching names.
+	deputyIDFilter   map[string]bool
+	deputyNameFilter *regexp.Regexp
+
+	// limit keeps only the first deputies that pass the filters; 0 keeps all of them.
+	limit int
+
+	idListRegex = regexp.MustCompile(`^\s*\d+(\s*,\s*\d+)*\s*$`)
+)
+
+// parseDeputyFilter takes either a comma separated list of IDs or a case insensitive name regex.
+func parseDeputyFilter(v string) error {
+	if idListRegex.MatchString(v) {
+		deputyIDFilter = map[string]bool{}
+		for _, id := range splitList(v) {
+			deputyIDFilter[id] = true
+		}
+
+		return nil
+	}
+
+	re, err := regexp.Compile("(?i)" + v)
+	if err != nil {
+		return err
+	}
+	deputyNameFilter = re
+
+	return nil
+}
+
+func splitList(v string) []string {
+	var values []string
+	for _, value := range strings.Split(v, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func parseUFs(v string) ([]string, error) {
+	known := map[string]bool{}
+	for _, uf := range states {
+		known[uf] = true
+	}
+
+	var ufs []string
+	for _, uf := range splitList(v) {
+		uf = strings.ToUpper(uf)
+		if !known[uf] {
+			return nil, fmt.Errorf("unknown state %q", uf)
+		}
+		ufs = append(ufs, uf)
+	}
+
+	return ufs, nil
+}
+
+func containsFold(values []string, v string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesFilters reports whether a listed deputy passes the command line filters.
+func matchesFilters(d *Deputy) bool {
+	if len(ufFilter) > 0 && !containsFold(ufFilter, d.State) {
+		return false
+	}
+
+	if len(partyFilter) > 0 && !containsFold(partyFilter, d.PoliticalParty) {
+		return false
+	}
+
+	if deputyIDFilter != nil && !deputyIDFilter[d.ID] {
+		return false
+	}
+
+	if deputyNameFilter != nil && !deputyNameFilter.MatchString(d.Name) {
+		return false
+	}
+
+	return true
+}
+
+// selectDeputies applies the command line filters and -limit to the listed deputies.
+func selectDeputies(deputies []*Deputy) []*Deputy {
+	var selected []*Deputy
+	for _, d := range deputies {
+		if limit > 0 && len(selected) >= limit {
+			break
+		}
+		if matchesFilters(d) {
+			selected = append(selected, d)
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesFilters(t *testing.T) {
+	defer func() {
+		ufFilter, partyFilter = nil, nil
+		deputyIDFilter, deputyNameFilter = nil, nil
+	}()
+
+	deputy := &Deputy{ID: "1", Name: "Fulano", PoliticalParty: "PCdoB", State: "BA"}
+	assert.True(t, matchesFilters(deputy))
+
+	ufFilter = []string{"SP", "BA"}
+	assert.True(t, matchesFilters(deputy))
+
+	partyFilter = []string{"pcdob"}
+	assert.True(t, matchesFilters(deputy))
+
+	partyFilter = []string{"PT"}
+	assert.False(t, matchesFilters(deputy))
+
+	partyFilter, ufFilter = nil, []string{"RJ"}
+	assert.False(t, matchesFilters(deputy))
+
+	ufFilter = nil
+	assert.NoError(t, parseDeputyFilter("2, 1"))
+	assert.True(t, matchesFilters(deputy))
+	assert.False(t, matchesFilters(&Deputy{ID: "3"}))
+
+	deputyIDFilter = nil
+	assert.NoError(t, parseDeputyFilter("^fula"))
+	assert.True(t, matchesFilters(deputy))
+	assert.False(t, matchesFilters(&Deputy{ID: "3", Name: "Beltrano"}))
+}
+
+func TestSelectDeputies(t *testing.T) {
+	defer func() {
+		ufFilter, limit = nil, 0
+	}()
+
+	deputies := []*Deputy{
+		{ID: "1", State: "SP"},
+		{ID: "2", State: "RJ"},
+		{ID: "3", State: "SP"},
+		{ID: "4", State: "SP"},
+	}
+
+	assert.Equal(t, deputies, selectDeputies(deputies))
+
+	ufFilter, limit = []string{"SP"}, 2
+	assert.Equal(t, []*Deputy{deputies[0], deputies[2]}, selectDeputies(deputies))
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+)
+
+func registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&configFile, "config", configFile, "YAML file setting any of these options by flag name; GODEPUTY_* variables and command line flags take precedence")
+	fs.IntVar(&workers, "workers", workers, "number of deputy pages fetched concurrently")
+	fs.IntVar(&queueSize, "queue-size", queueSize, "number of scraped deputies written together in a batch")
+	fs.DurationVar(&flushInterval, "flush-interval", flushInterval, "maximum time a partial batch of deputies waits before being written")
+	fs.StringVar(&outputDir, "out", outputDir, "directory the output files are written to, created if missing")
+	fs.StringVar(&outputDir, "o", outputDir, "shorthand for -out; - writes the file of the single -format json, ndjson, csv or md to stdout and only logs errors")
+	funcFlag(fs, "legislatura", "legislature to scrape, or several as a list or range, e.g. 55,56,57 or 55-57, each scraped for every year it covers (default 57)", func(v string) (err error) {
+		legislatures, err = parseLegislatures(v)
+		if err == nil {
+			legislatury = legislatures[0]
+		}
+		return err
+	})
+	funcFlag(fs, "ano", "year or range of years to scrape, e.g. 2024 or 2019-2024, scraped in the legislature of each year unless -legislatura is given (default 2024)", func(v string) (err error) {
+		years, err = parseYears(v)
+		if err == nil {
+			year = years[0]
+		}
+		return err
+	})
+	funcFlag(fs, "uf", "comma separated states (UF) to scrape, e.g. SP,RJ", func(v string) (err error) {
+		ufFilter, err = parseUFs(v)
+		return err
+	})
+	funcFlag(fs, "partido", "comma separated political parties to scrape, e.g. PT,PL", func(v string) error {
+		partyFilter = splitList(v)
+		return nil
+	})
+	funcFlag(fs, "deputado", "comma separated deputy IDs or a name regex of the deputies to scrape", parseDeputyFilter)
+	funcFlag(fs, "mes", "month (1-12) or comma separated months to scrape instead of the whole year", func(v string) (err error) {
+		months, err = parseMonths(v)
+		return err
+	})
+	funcFlag(fs, "period", "rolling period of up to 12 months scraped instead of -ano and -mes, which may cross the turn of the year, e.g. 2023-07:2024-06", func(v string) (err error) {
+		period, err = parsePeriod(v)
+		periodLabel = v
+		return err
+	})
+	fs.BoolVar(&perCapita, "per-capita", false, "write state_per_capita.json with each state's spending divided by its population")
+	fs.StringVar(&populationFile, "population-file", "", "JSON file mapping state (UF) to population, overriding the embedded census table")
+	funcFlag(fs, "quota-selector", "additional fallback selector for the parliamentary quota total (repeatable)", func(v string) error {
+		parliamentaryQuotaSelectors = append(parliamentaryQuotaSelectors, selector.QueryString(v))
+		return nil
+	})
+	funcFlag(fs, "format", "comma separated artifacts to write: json, ndjson (deputies.ndjson appended as each batch is flushed), csv (deputies.csv and cost_details.csv described by datapackage.json), long (expenses_long.csv with one row per deputy, month and category), xlsx (deputies.xlsx with Deputies, Party Totals and Cost Details sheets), parquet (deputies.parquet and expenses.parquet), avro (deputies.avro and expenses.avro), md (summary.md), pdf (report.pdf) and png or svg (default json,csv,png)", func(v string) (err error) {
+		outputFormats, err = parseFormats(v)
+		return err
+	})
+	fs.StringVar(&chartFormat, "chart-format", chartFormat, "chart output format: png or svg")
+	fs.BoolVar(&withDetails, "with-details", withDetails, "collect the parliamentary quota details of each deputy")
+	fs.BoolVar(&monthlyBreakdown, "monthly", false, "visit every month (or each month of -mes) of each deputy and store monthlyTotals and monthlyQuotaDetails")
+	fs.BoolVar(&withDocuments, "documents", false, "keep every expense row, with its date, supplier, document number and receipt URL, under the quota category it belongs to; the html source visits the expense page of each category")
+	fs.BoolVar(&withProfile, "with-profile", false, "also fetch the civil name, birth date and place, gender, education, photo, email and cabinet of each deputy from the Dados Abertos API, and write demographics.json with the spending by gender, education and birth state")
+	fs.BoolVar(&withAttendance, "with-attendance", false, "also visit the plenary attendance page of each deputy and record presence and absence days")
+	fs.BoolVar(&withVotes, "with-votes", false, "also count the plenary roll-call votes of each deputy in the period from the Dados Abertos API")
+	fs.BoolVar(&withMandate, "with-mandate", false, "also fetch whether each deputy is a titular or suplente, the periods in exercise in the year and the cost per day in office")
+	fs.BoolVar(&withPartyHistory, "with-party-history", false, "also record the party changes of each deputy in the legislature and the party it was elected by; with -monthly, political_party_total_by_affiliation.json attributes each month to the party held then")
+	fs.BoolVar(&withCommittees, "with-committees", false, "also record the permanent committees each deputy sat on in the year, and write committee_total.json")
+	fs.BoolVar(&withProductivity, "with-productivity", false, "also count the bills and amendments each deputy authored in the year, and the cost per proposition")
+	fs.BoolVar(&withCampaign, "with-campaign", false, "also join each deputy by CPF (with -with-profile) or name with the campaign revenue and expenses it declared to the TSE for the election of the legislature, and write campaign_vs_mandate.json")
+	fs.StringVar(&tseFile, "tse-file", "", "TSE campaign accounts dump (prestacao_de_contas_eleitorais_candidatos_XXXX.zip) to read instead of downloading the one of the election")
+	fs.StringVar(&tseURL, "tse-url", tseURL, "URL of the TSE campaign accounts dump, with %d replaced by the election year")
+	fs.BoolVar(&withStaff, "with-staff", false, "also visit the cabinet staff page of each deputy to break the office budget down per employee")
+	funcFlag(fs, "output", "also write the deputies of the run to kind=target (repeatable): sqlite=./deputies.db stores the deputies, expenses and parties tables, postgres=DSN upserts the deputies and expenses tables, gsheet=SPREADSHEET_ID replaces the Deputies and Party Totals sheets", parseOutput)
+	fs.StringVar(&googleCredentials, "google-credentials", "", "JSON key of the service account used by -output gsheet, -bigquery-table and -publish gs://, a spreadsheet must be shared with it (default application default credentials)")
+	fs.StringVar(&bigqueryProject, "bigquery-project", "", "Google Cloud project of -bigquery-dataset (default the project of the credentials)")
+	fs.StringVar(&bigqueryDataset, "bigquery-dataset", "", "BigQuery dataset of -bigquery-table")
+	fs.StringVar(&bigqueryTable, "bigquery-table", "", "BigQuery table each flushed batch of deputies is streamed into, created in -bigquery-dataset when missing")
+	fs.StringVar(&postgresDSN, "postgres-dsn", "", "PostgreSQL connection string the deputies and expenses are upserted into, same as -output postgres=DSN (best set as GODEPUTY_POSTGRES_DSN)")
+	fs.StringVar(&sheetURL, "sheet-url", "", "endpoint (e.g. an Apps Script webhook) that receives each batch of deputies via POST")
+	fs.StringVar(&sheetFormat, "sheet-format", sheetFormat, "format of the rows posted to -sheet-url: json or csv")
+	fs.BoolVar(&sortByID, "sort-by-id", false, "sort deputies by ID before writing so repeated runs produce identical output")
+	fs.BoolVar(&byState, "by-state", false, "also walk the listing filtered by each state (UF) and merge any deputies missing from the unfiltered page")
+	fs.StringVar(&moneyParser, "money-parser", moneyParser, "parser for monetary values: default or x-text")
+	fs.BoolVar(&resume, "resume", false, "continue an interrupted run, only fetching the deputies missing from its checkpoint")
+	fs.BoolVar(&dryRun, "dry-run", false, "print the deputies found on the listing page and exit without fetching their details")
+	fs.BoolVar(&checkLayout, "check-layout", false, "fail the run when a selector of the deputy page matches nothing on every page visited, saving a page that missed it into layout/ under -out")
+	fs.BoolVar(&listPeriods, "list-periods", false, "print the legislatures and years available on the site and exit")
+	fs.DurationVar(&perRequestTimeout, "per-request-timeout", 0, "abandon a deputy detail page that takes longer than this (0 disables)")
+	fs.IntVar(&retries, "retries", 2, "times a deputy detail page that timed out is fetched again")
+	fs.IntVar(&sinkRetries, "sink-retries", sinkRetries, "times a failed output sink write is retried before the batch is dead-lettered")
+	fs.DurationVar(&sinkBackoff, "sink-backoff", sinkBackoff, "initial delay between output sink retries, doubled after each attempt")
+	funcFlag(fs, "refresh-older-than", "only scrape deputies whose record in the previous deputies.json is older than this (e.g. 7d, 12h)", func(v string) (err error) {
+		refreshOlderThan, err = parseAge(v)
+		return err
+	})
+	fs.BoolVar(&verbose, "v", false, "shorthand for -log-level debug")
+	fs.BoolVar(&verbose, "verbose", false, "shorthand for -log-level debug")
+	fs.BoolVar(&quiet, "q", false, "shorthand for -log-level error")
+	fs.StringVar(&logLevelName, "log-level", logLevelName, "minimum level logged to stderr: debug (visited URLs, redirects and selector hits), info, warn or error")
+	fs.BoolVar(&categoryCharts, "category-charts", false, "render a bar chart of the top spenders of each quota category into charts/")
+	fs.IntVar(&categoryTop, "category-top", categoryTop, "number of deputies shown in each category chart")
+	fs.IntVar(&chartTop, "chart-top", chartTop, "number of deputies shown in the top_deputies chart of the biggest spenders, 0 to skip it")
+	fs.StringVar(&archivePath, "archive", "", "also bundle every output file into this zip archive")
+	fs.StringVar(&publishURL, "publish", "", "upload the output files, the manifest and -archive after the run to s3://bucket/prefix or gs://bucket/prefix")
+	funcFlag(fs, "name-template", "name of the output files, with the placeholders {name}, {legislatura}, {ano}, {date} and {time} of the run, e.g. {name}_{legislatura}_{ano}_{date} (default {name})", parseNameTemplate)
+	fs.BoolVar(&compressOutput, "compress", false, "gzip the JSON and CSV outputs, writing them as .json.gz and .csv.gz")
+	fs.BoolVar(&backupOutputs, "backup", false, "keep the previous version of every output file the run replaces as .bak")
+	fs.BoolVar(&fullHistory, "full-history", false, "scrape every legislature and year offered by the site into per-period directories, resuming from the last checkpoint")
+	fs.DurationVar(&requestInterval, "rate-limit", requestInterval, "minimum interval between requests to the site (0 disables)")
+	fs.DurationVar(&requestJitter, "rate-jitter", 0, "random extra delay of up to this much added to each -rate-limit interval")
+	fs.BoolVar(&respectRobots, "respect-robots", false, "check the robots.txt of each site before requesting a page, skip the disallowed ones and honour its Crawl-delay")
+	fs.StringVar(&userAgent, "user-agent", userAgent, "User-Agent header sent with every request and matched against robots.txt")
+	fs.IntVar(&maxRedirects, "max-redirects", maxRedirects, "maximum number of redirects followed for a single page")
+	fs.StringVar(&baseURL, "base-url", baseURL, "base URL of the Câmara site")
+	funcFlag(fs, "source", "legislative house to collect: camara, senado, alesp or any other registered source; for the Câmara, where deputies and costs come from: html (transparency portal), api (Dados Abertos, which has no salary or office budget) or both in fallback order, e.g. api,html or camara,api (default camara,html)", parseSources)
+	fs.StringVar(&apiURL, "api-url", apiURL, "base URL of the Câmara Dados Abertos API")
+	fs.BoolVar(&validateOutput, "validate-output", false, "validate deputies.json against the embedded JSON schema before writing it and fail the run if it does not match")
+	funcFlag(fs, "fields", "comma separated figures to collect: salary, office, quota, details, tickets (air tickets paid by the quota), travel and housing (default all)", func(v string) (err error) {
+		fields, err = parseFields(v)
+		return err
+	})
+	fs.IntVar(&limit, "limit", 0, "only scrape the first N deputies that pass the filters (0 scrapes all of them)")
+	funcFlag(fs, "lang", "language of chart titles, labels and report headings: pt or en (default pt)", parseLang)
+	fs.StringVar(&pprofAddr, "pprof", "", "serve net/http/pprof on this address (e.g. :6060) while the command runs")
+	fs.StringVar(&cpuProfile, "cpuprofile", "", "write a CPU profile of the run to this file")
+	fs.StringVar(&memProfile, "memprofile", "", "write a heap profile to this file when the run finishes")
+	fs.StringVar(&mergeFiles, "merge", "", "comma separated deputies.json files to merge into a single output instead of scraping")
+}
+
+// registerCommandFlags adds the flags that only make sense for one command.
+func registerCommandFlags(command string, fs *flag.FlagSet) {
+	switch command {
+	case "report":
+		fs.StringVar(&reportInput, "input", "", "deputies.json to build the report from (default <out>/deputies.json)")
+		fs.IntVar(&reportTop, "top", reportTop, "number of deputies listed among the top spenders of the html report")
+	case "serve":
+		fs.StringVar(&serveAddr, "addr", serveAddr, "address the HTTP server listens on")
+	case "ceap":
+		fs.StringVar(&ceapFile, "ceap-file", "", "CEAP dump (Ano-XXXX.csv or .csv.zip) to read instead of downloading the one of -ano")
+		fs.StringVar(&ceapURL, "ceap-url", ceapURL, "URL of the yearly CEAP dump, with %d replaced by the year")
+	case "senado":
+		fs.StringVar(&senadoFile, "ceaps-file", "", "CEAPS dump (despesa_ceaps_XXXX.csv) to read instead of downloading the one of -ano")
+		fs.StringVar(&senadoCEAPSURL, "ceaps-url", senadoCEAPSURL, "URL of the yearly CEAPS dump, with %d replaced by the year")
+		fs.StringVar(&senadoAPIURL, "senado-api-url", senadoAPIURL, "base URL of the Senado Dados Abertos API, used to list the senators")
+	case "alesp":
+		fs.StringVar(&alespURL, "alesp-url", alespURL, "base URL of the ALESP open data repository")
+		fs.StringVar(&alespExpensesFile, "alesp-expenses-file", "", "ALESP office expenses dump (despesas_gabinetes.xml) to read instead of downloading it")
+	}
+}
+
+// applyFlags validates the parsed flags of command and sets up what depends on them.
+func applyFlags(command string) error {
+	if err := setLogLevel(); err != nil {
+		return err
+	}
+
+	if workers < 1 || queueSize < 1 || flushInterval <= 0 {
+		return fmt.Errorf("-workers, -queue-size and -flush-interval must be positive")
+	}
+
+	if limit < 0 {
+		return fmt.Errorf("-limit must not be negative")
+	}
+
+	if err := setupStdout(command); err != nil {
+		return err
+	}
+
+	switch {
+	case outputFormats["png"] && outputFormats["svg"]:
+		return fmt.Errorf("-format accepts either png or svg, not both")
+	case outputFormats["png"]:
+		chartFormat = "png"
+	case outputFormats["svg"]:
+		chartFormat = "svg"
+	}
+
+	if chartFormat != "png" && chartFormat != "svg" {
+		return fmt.Errorf("invalid chart format %q, expected png or svg", chartFormat)
+	}
+
+	if sheetFormat != "json" && sheetFormat != "csv" {
+		return fmt.Errorf("invalid sheet format %q, expected json or csv", sheetFormat)
+	}
+
+	parser, ok := moneyParsers[moneyParser]
+	if !ok {
+		return fmt.Errorf("invalid money parser %q, expected default or x-text", moneyParser)
+	}
+	parseMoney = parser
+
+	deputySinks = append(deputySinks, deputySink{Name: "checkpoint", Write: writeCheckpoint})
+
+	if wantFormat("ndjson") {
+		deputySinks = append(deputySinks, deputySink{Name: "ndjson", Write: writeNDJSON})
+	}
+
+	if postgresDSN != "" {
+		outputTargets = append(outputTargets, outputTarget{Kind: "postgres", Target: postgresDSN})
+	}
+
+	if sheetURL != "" {
+		deputySinks = append(deputySinks, deputySink{Name: "sheet", Write: writeSheet})
+	}
+
+	if (bigqueryDataset == "") != (bigqueryTable == "") {
+		return fmt.Errorf("-bigquery-dataset and -bigquery-table must be given together")
+	}
+	if bigqueryTable != "" {
+		deputySinks = append(deputySinks, deputySink{Name: "bigquery", Write: writeBigQuery})
+	}
+
+	if publishURL != "" {
+		target, err := parsePublish(publishURL)
+		if err != nil {
+			return err
+		}
+		publish = target
+	}
+
+	return nil
+}
+
+func parseMonths(v string) ([]int, error) {
+	var months []int
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		month, err := strconv.Atoi(s)
+		if err != nil || month < 1 || month > 12 {
+			return nil, fmt.Errorf("invalid month %q", s)
+		}
+		months = append(months, month)
+	}
+
+	return months, nil
+}
+
+func parseYears(v string) ([]int, error) {
+	first, last, isRange := strings.Cut(v, "-")
+
+	from, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		return nil, fmt.Errorf("invalid year %q", v)
+	}
+
+	to := from
+	if isRange {
+		to, err = strconv.Atoi(strings.TrimSpace(last))
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid year range %q", v)
+		}
+	}
+
+	var years []int
+	for y := from; y <= to; y++ {
+		years = append(years, y)
+	}
+
+	return years, nil
+}
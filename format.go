@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+)
+
+var (
+	// outputFormats holds the artifacts selected with -format; nil writes the defaults.
+	outputFormats map[string]bool
+
+	// defaultFormats are the artifacts written when -format is not given.
+	defaultFormats = map[string]bool{"json": true, "csv": true, "png": true}
+)
+
+func parseFormats(v string) (map[string]bool, error) {
+	formats := map[string]bool{}
+	for _, f := range splitList(v) {
+		switch f {
+		case "json", "ndjson", "csv", "long", "xlsx", "parquet", "avro", "md", "pdf", "png", "svg":
+			formats[f] = true
+		default:
+			return nil, fmt.Errorf("unknown format %q, expected json, ndjson, csv, long, xlsx, parquet, avro, md, pdf, png or svg", f)
+		}
+	}
+
+	return formats, nil
+}
+
+func wantFormat(f string) bool {
+	if outputFormats == nil {
+		return defaultFormats[f]
+	}
+
+	return outputFormats[f]
+}
+
+func wantChart() bool {
+	return outputFormats == nil || outputFormats["png"] || outputFormats["svg"]
+}
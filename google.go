@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// googleCredentials is the JSON key of the service account the gsheet output, the
+// BigQuery sink and -publish gs:// authenticate with. The spreadsheet must be shared
+// with its e-mail.
+var googleCredentials string
+
+// googleClient authenticates with -google-credentials, or with the application default
+// credentials when it is not set, returning the project of the credentials.
+func googleClient(ctx context.Context, scope string) (*http.Client, string, error) {
+	var credentials *google.Credentials
+	if googleCredentials == "" {
+		c, err := google.FindDefaultCredentials(ctx, scope)
+		if err != nil {
+			return nil, "", err
+		}
+		credentials = c
+	} else {
+		data, err := os.ReadFile(googleCredentials)
+		if err != nil {
+			return nil, "", err
+		}
+
+		c, err := google.CredentialsFromJSON(ctx, data, scope)
+		if err != nil {
+			return nil, "", err
+		}
+		credentials = c
+	}
+
+	return oauth2.NewClient(ctx, credentials.TokenSource), credentials.ProjectID, nil
+}
+
+// googleAPI calls a JSON REST API of Google Cloud under url.
+type googleAPI struct {
+	client *http.Client
+	url    string
+}
+
+// googleAPIError is the error a Google API answered with.
+type googleAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *googleAPIError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.StatusCode, e.Message)
+}
+
+func (api *googleAPI) call(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, api.url+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return api.do(req, out)
+}
+
+// do sends req, decoding the JSON answer into out unless it is nil.
+func (api *googleAPI) do(req *http.Request, out any) error {
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		return &googleAPIError{StatusCode: resp.StatusCode, Message: apiErr.Error.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const googleSheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+var (
+	googleSheetsURL = "https://sheets.googleapis.com/v4/spreadsheets/"
+
+	newGoogleSheetsClient = func(ctx context.Context) (*http.Client, error) {
+		client, _, err := googleClient(ctx, googleSheetsScope)
+		return client, err
+	}
+)
+
+// sheetTitles returns the titles of the sheets the spreadsheet already has.
+func (api *googleAPI) sheetTitles(ctx context.Context) (map[string]bool, error) {
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := api.call(ctx, http.MethodGet, "?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return nil, err
+	}
+
+	titles := map[string]bool{}
+	for _, s := range spreadsheet.Sheets {
+		titles[s.Properties.Title] = true
+	}
+
+	return titles, nil
+}
+
+// googleSheetLastColumn is the last column a sheet can have, ending the ranges cleared
+// past the values written.
+const googleSheetLastColumn = "ZZZ"
+
+// googleSheetRange quotes the sheet name for A1 notation, as it may contain spaces.
+func googleSheetRange(sheet, cells string) string {
+	r := "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	if cells != "" {
+		r += "!" + cells
+	}
+
+	return r
+}
+
+// writeGoogleSheet replaces the Deputies and Party Totals sheets of the spreadsheet
+// with the deputies of the run, adding the sheets it does not have yet, so a shared
+// spreadsheet always shows the last run. Other sheets are left alone. The values are
+// written over the previous ones before the cells past them are cleared, so a failed
+// write leaves the last run in place rather than empty sheets.
+func writeGoogleSheet(ctx context.Context, spreadsheetID string, deputies []*Deputy) error {
+	client, err := newGoogleSheetsClient(ctx)
+	if err != nil {
+		return fmt.Errorf("error.gsheet.credentials: %v", err)
+	}
+
+	api := &googleAPI{client: client, url: googleSheetsURL + url.PathEscape(spreadsheetID)}
+
+	partyMap := map[string][]*Deputy{}
+	for _, d := range deputies {
+		partyMap[d.PoliticalParty] = append(partyMap[d.PoliticalParty], d)
+	}
+	sheets := xlsxSheets(deputies, partyMap)[:2]
+
+	titles, err := api.sheetTitles(ctx)
+	if err != nil {
+		return fmt.Errorf("error.gsheet.get: %v", err)
+	}
+
+	var requests []any
+	for _, s := range sheets {
+		if !titles[s.Name] {
+			requests = append(requests, map[string]any{
+				"addSheet": map[string]any{"properties": map[string]any{"title": s.Name}},
+			})
+		}
+	}
+	if len(requests) > 0 {
+		if err := api.call(ctx, http.MethodPost, ":batchUpdate", map[string]any{"requests": requests}, nil); err != nil {
+			return fmt.Errorf("error.gsheet.add: %v", err)
+		}
+	}
+
+	var ranges []string
+	var data []any
+	for _, s := range sheets {
+		values := [][]any{{}}
+		for _, h := range s.Header {
+			values[0] = append(values[0], h)
+		}
+		values = append(values, s.Rows...)
+
+		data = append(data, map[string]any{"range": googleSheetRange(s.Name, "A1"), "values": values})
+
+		next, err := excelize.ColumnNumberToName(len(s.Header) + 1)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges,
+			googleSheetRange(s.Name, fmt.Sprintf("A%d:%s", len(values)+1, googleSheetLastColumn)),
+			googleSheetRange(s.Name, fmt.Sprintf("%s1:%s", next, googleSheetLastColumn)),
+		)
+	}
+
+	body := map[string]any{"valueInputOption": "RAW", "data": data}
+	if err := api.call(ctx, http.MethodPost, "/values:batchUpdate", body, nil); err != nil {
+		return fmt.Errorf("error.gsheet.update: %v", err)
+	}
+
+	if err := api.call(ctx, http.MethodPost, "/values:batchClear", map[string]any{"ranges": ranges}, nil); err != nil {
+		return fmt.Errorf("error.gsheet.clear: %v", err)
+	}
+
+	infof("wrote %d deputies to google sheet %s", len(deputies), spreadsheetID)
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var fullHistory bool
+
+type Period struct {
+	Legislature int `json:"legislature"`
+	Year        int `json:"year"`
+}
+
+func (p Period) Dir() string {
+	return fmt.Sprintf("%d-%d", p.Legislature, p.Year)
+}
+
+type HistoryIndexEntry struct {
+	Period
+	Dir      string  `json:"dir"`
+	Deputies int     `json:"deputies"`
+	Total    float64 `json:"total"`
+}
+
+type historyCheckpoint struct {
+	Completed []HistoryIndexEntry `json:"completed"`
+}
+
+// legislatureYears returns the four years covered by a legislature;
+// the 57th, for instance, runs from 2023 to 2026.
+func legislatureYears(legislature int) (first, last int) {
+	first = 1795 + 4*legislature
+
+	return first, first + 3
+}
+
+func legislatureOfYear(year int) int {
+	return (year - 1795) / 4
+}
+
+// historyPeriods pairs every legislature offered by the site with the offered years it covers.
+func historyPeriods(ctx context.Context) ([]Period, error) {
+	legislatures, years, err := getPeriods(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var periods []Period
+	for _, l := range legislatures {
+		legislature, err := strconv.Atoi(l.Value)
+		if err != nil {
+			continue
+		}
+
+		first, last := legislatureYears(legislature)
+		for _, y := range years {
+			year, err := strconv.Atoi(y.Value)
+			if err != nil || year < first || year > last {
+				continue
+			}
+			periods = append(periods, Period{Legislature: legislature, Year: year})
+		}
+	}
+
+	return periods, nil
+}
+
+func loadHistoryCheckpoint(name string) (historyCheckpoint, error) {
+	var checkpoint historyCheckpoint
+
+	bytes, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+
+	err = json.Unmarshal(bytes, &checkpoint)
+
+	return checkpoint, err
+}
+
+func writeJSONFile(name string, v any) error {
+	bytes, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(name, bytes)
+}
+
+// scrapeFullHistory scrapes every period into its own directory under outputDir.
+// Finished periods are checkpointed so an interrupted crawl resumes where it stopped.
+func scrapeFullHistory(ctx context.Context) error {
+	periods, err := historyPeriods(ctx)
+	if err != nil {
+		return err
+	}
+
+	baseDir := outputDir
+	defer func() {
+		outputDir = baseDir
+	}()
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return err
+	}
+
+	checkpointFile := filepath.Join(baseDir, "full_history_checkpoint.json")
+	checkpoint, err := loadHistoryCheckpoint(checkpointFile)
+	if err != nil {
+		return err
+	}
+
+	completed := map[Period]bool{}
+	for _, entry := range checkpoint.Completed {
+		completed[entry.Period] = true
+	}
+
+	failed := 0
+	for _, period := range periods {
+		if completed[period] {
+			infof("skipping %s, already scraped", period.Dir())
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		infof("scraping legislature %d year %d", period.Legislature, period.Year)
+
+		legislatury, year = period.Legislature, period.Year
+		outputDir = filepath.Join(baseDir, period.Dir())
+
+		// a period that missed deputies is not checkpointed, so the next run scrapes it again
+		var partial *partialFailureError
+		err := scrape(ctx)
+		if errors.As(err, &partial) {
+			failed += partial.Failed
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := HistoryIndexEntry{
+			Period:   period,
+			Dir:      period.Dir(),
+			Deputies: len(deputiesArray),
+		}
+		for _, d := range deputiesArray {
+			entry.Total += d.Total
+		}
+
+		checkpoint.Completed = append(checkpoint.Completed, entry)
+		if err := writeJSONFile(checkpointFile, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSONFile(filepath.Join(baseDir, "index.json"), checkpoint.Completed); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return &partialFailureError{Failed: failed}
+	}
+
+	return nil
+}
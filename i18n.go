@@ -0,0 +1,80 @@
+package main
+
+import "fmt"
+
+// lang selects the language of chart titles, labels and report headings.
+var lang = "pt"
+
+var messages = map[string]map[string]string{
+	"pt": {
+		"chart.party.title":  "Gastos por partido político",
+		"chart.party.others": "Outros",
+		"chart.top.title":    "Deputados que mais gastaram",
+
+		"report.title":       "Gastos dos deputados federais",
+		"report.legislature": "Legislatura",
+		"report.generated":   "Gerado em",
+		"report.parties":     "Gastos por partido",
+		"report.states":      "Gastos por estado",
+		"report.top":         "Deputados que mais gastaram",
+		"report.categories":  "Categorias de maior gasto",
+		"report.party":       "Partido",
+		"report.state":       "Estado",
+		"report.name":        "Nome",
+		"report.category":    "Categoria",
+		"report.deputies":    "deputados",
+		"report.total":       "Total",
+		"report.mean":        "Média",
+		"report.median":      "Mediana",
+		"report.run":         "Execução",
+		"report.started":     "Início",
+		"report.finished":    "Fim",
+		"report.months":      "Meses",
+		"report.fields":      "Campos",
+	},
+	"en": {
+		"chart.party.title":  "Spending by political party",
+		"chart.party.others": "Others",
+		"chart.top.title":    "Top spending deputies",
+
+		"report.title":       "Federal deputies spending",
+		"report.legislature": "Legislature",
+		"report.generated":   "Generated at",
+		"report.parties":     "Spending by party",
+		"report.states":      "Spending by state",
+		"report.top":         "Top spenders",
+		"report.categories":  "Most expensive categories",
+		"report.party":       "Party",
+		"report.state":       "State",
+		"report.name":        "Name",
+		"report.category":    "Category",
+		"report.deputies":    "deputies",
+		"report.total":       "Total",
+		"report.mean":        "Mean",
+		"report.median":      "Median",
+		"report.run":         "Run",
+		"report.started":     "Started",
+		"report.finished":    "Finished",
+		"report.months":      "Months",
+		"report.fields":      "Fields",
+	},
+}
+
+func parseLang(v string) error {
+	if _, ok := messages[v]; !ok {
+		return fmt.Errorf("unknown language %q, expected pt or en", v)
+	}
+
+	lang = v
+
+	return nil
+}
+
+// translate returns the text of key in the selected language, falling back to Portuguese.
+func translate(key string) string {
+	if s, ok := messages[lang][key]; ok {
+		return s
+	}
+
+	return messages["pt"][key]
+}
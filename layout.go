@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// checkLayout fails the scrape when a selector of the deputy page matched no node on any
+// page visited, which is how a change of the site's HTML shows up instead of zero fields.
+var checkLayout bool
+
+// layoutMiss is a selector that matched nothing, with the first page it was missing from
+// and the file that page was dumped to.
+type layoutMiss struct {
+	Field    string
+	Query    string
+	URL      string
+	DumpFile string
+}
+
+var (
+	layoutMutex   sync.Mutex
+	layoutMatched map[string]bool
+	layoutMisses  map[string]*layoutMiss
+)
+
+// layoutError reports the selectors that matched nothing on every page of the run.
+type layoutError struct {
+	Misses []*layoutMiss
+}
+
+func (e *layoutError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "error.layout.changed: %d selectors matched no node on any deputy page", len(e.Misses))
+	for _, m := range e.Misses {
+		fmt.Fprintf(&b, "\n  %s: zero matches for selector %q on page %s (saved to %s)", m.Field, m.Query, m.URL, m.DumpFile)
+	}
+
+	return b.String()
+}
+
+func resetLayoutCheck() {
+	layoutMutex.Lock()
+	defer layoutMutex.Unlock()
+
+	layoutMatched = map[string]bool{}
+	layoutMisses = map[string]*layoutMiss{}
+}
+
+// recordLayout adds the selector matches of a visited deputy page to the check, dumping
+// the page into layout/ under -out the first time a selector is missing from it. The quota
+// total is looked up through its fallback chain, so it counts when any of them matches.
+func recordLayout(deputy *Deputy, page *html.Node, extractors []deputyExtractor, matches []int) {
+	if page == nil {
+		return
+	}
+
+	layoutMutex.Lock()
+	defer layoutMutex.Unlock()
+
+	for i, e := range extractors {
+		query, matched := string(e.Query), matches[i]
+		if e.Name == "quota" {
+			query, matched = quotaLayoutQuery(), 0
+			for _, q := range parliamentaryQuotaSelectors {
+				matched += len(q.Select(page))
+			}
+		}
+
+		if matched > 0 {
+			layoutMatched[e.Name] = true
+			continue
+		}
+
+		if _, ok := layoutMisses[e.Name]; ok {
+			continue
+		}
+
+		miss := &layoutMiss{Field: e.Name, Query: query, URL: deputy.SourceURL}
+		miss.DumpFile = dumpLayoutPage(e.Name, deputy, page)
+		layoutMisses[e.Name] = miss
+	}
+}
+
+func quotaLayoutQuery() string {
+	var queries []string
+	for _, q := range parliamentaryQuotaSelectors {
+		queries = append(queries, string(q))
+	}
+
+	return strings.Join(queries, " | ")
+}
+
+// dumpLayoutPage writes the page of deputy missing field to layout/, returning its path.
+// The dumps are for debugging, so they are kept out of the outputs of the run: they are
+// neither templated, compressed, listed in the manifest nor published.
+func dumpLayoutPage(field string, deputy *Deputy, page *html.Node) string {
+	var buffer bytes.Buffer
+	if err := html.Render(&buffer, page); err != nil {
+		errorf("%v", err)
+		return ""
+	}
+
+	path := filepath.Join(outputDir, "layout", field+"_"+deputy.ID+".html")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		errorf("%v", err)
+		return ""
+	}
+
+	if err := writeFileAtomic(path, buffer.Bytes()); err != nil {
+		errorf("%v", err)
+		return ""
+	}
+
+	return path
+}
+
+// layoutReport returns a layoutError listing the selectors that never matched, or nil
+// when -check-layout is off or every selector matched on some page.
+func layoutReport() error {
+	if !checkLayout {
+		return nil
+	}
+
+	layoutMutex.Lock()
+	defer layoutMutex.Unlock()
+
+	var broken []*layoutMiss
+	for field, miss := range layoutMisses {
+		if !layoutMatched[field] {
+			broken = append(broken, miss)
+		}
+	}
+	if len(broken) == 0 {
+		return nil
+	}
+
+	sort.Slice(broken, func(i, j int) bool {
+		return broken[i].Field < broken[j].Field
+	})
+
+	return &layoutError{Misses: broken}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// legislatures holds every legislature of -legislatura when more than one is given.
+var legislatures []int
+
+// legislatureFromYear is set when -ano is given without -legislatura, so each year is
+// scraped in the legislature it belongs to.
+var legislatureFromYear bool
+
+// resolveLegislature takes the legislature from -ano when -legislatura is not set, and
+// rejects the years of -ano that fall outside an explicit -legislatura.
+func resolveLegislature(set map[string]bool) error {
+	legislatureFromYear = false
+	if !set["ano"] {
+		return nil
+	}
+
+	if !set["legislatura"] {
+		legislatury, legislatureFromYear = legislatureOfYear(year), true
+		return nil
+	}
+
+	if len(legislatures) > 1 {
+		return fmt.Errorf("-ano cannot be combined with several legislatures, which are scraped for every year they cover")
+	}
+
+	first, last := legislatureYears(legislatury)
+	for _, y := range years {
+		if y < first || y > last {
+			return fmt.Errorf("year %d is not in legislature %d, which covers %d to %d", y, legislatury, first, last)
+		}
+	}
+
+	return nil
+}
+
+// parseLegislatures takes a legislature, a comma separated list or a range, e.g. 57, 55,56,57 or 55-57.
+func parseLegislatures(v string) ([]int, error) {
+	var parsed []int
+	for _, part := range splitList(v) {
+		values, err := parseYears(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid legislature %q", part)
+		}
+		for _, l := range values {
+			if l < 1 {
+				return nil, fmt.Errorf("invalid legislature %q", part)
+			}
+			parsed = append(parsed, l)
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("invalid legislature %q", v)
+	}
+
+	return parsed, nil
+}
+
+// scrapeLegislatures scrapes every year of each legislature into outputDir/<legislature>/<year>,
+// aggregates each legislature into outputDir/<legislature> and writes the outputs of all of
+// them, with the party totals of each legislature side by side, into outputDir.
+func scrapeLegislatures(ctx context.Context) error {
+	baseDir := outputDir
+	defer func() {
+		outputDir = baseDir
+	}()
+
+	var (
+		all         []*Deputy
+		partyTotals = map[int]map[string]float64{}
+		failed      int
+	)
+	for _, l := range legislatures {
+		first, last := legislatureYears(l)
+		if current := time.Now().Year(); last > current {
+			last = current
+		}
+
+		var legislatureDeputies []*Deputy
+		for y := first; y <= last; y++ {
+			legislatury, year = l, y
+			outputDir = filepath.Join(baseDir, strconv.Itoa(l), strconv.Itoa(y))
+
+			infof("scraping legislature %d year %d", legislatury, year)
+			var partial *partialFailureError
+			err := scrape(ctx)
+			if errors.As(err, &partial) {
+				failed += partial.Failed
+			} else if err != nil {
+				return err
+			}
+
+			legislatureDeputies = append(legislatureDeputies, deputiesArray...)
+		}
+
+		outputDir = filepath.Join(baseDir, strconv.Itoa(l))
+		if err := writeAggregatedDeputies(legislatureDeputies); err != nil {
+			return err
+		}
+		partyTotals[l] = politicalPartyTotalMap
+
+		all = append(all, legislatureDeputies...)
+	}
+
+	outputDir = baseDir
+
+	resetResults()
+	aggregateDeputies(all)
+
+	bytes, err := encodeOutputJSON(partyTotals)
+	if err != nil {
+		return err
+	}
+	if err := writeOutputFile("legislature_party_total.json", bytes); err != nil {
+		return err
+	}
+
+	if sortByID {
+		sortDeputies()
+	}
+	if err := writePoliticalPartyMap(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return &partialFailureError{Failed: failed}
+	}
+
+	return nil
+}
+
+func writeAggregatedDeputies(deputies []*Deputy) error {
+	resetResults()
+	aggregateDeputies(deputies)
+	if sortByID {
+		sortDeputies()
+	}
+
+	return writePoliticalPartyMap()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevelNames = map[string]logLevel{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+var (
+	logLevelName = "info"
+	quiet        bool
+
+	currentLogLevel = levelInfo
+
+	logger = log.New(os.Stderr, "", log.LstdFlags)
+)
+
+// setLogLevel applies -log-level, with -verbose and -q as shorthands for debug and error.
+func setLogLevel() error {
+	level, ok := logLevelNames[strings.ToLower(logLevelName)]
+	if !ok {
+		return fmt.Errorf("invalid log level %q, expected debug, info, warn or error", logLevelName)
+	}
+
+	switch {
+	case verbose:
+		level = levelDebug
+	case quiet:
+		level = levelError
+	}
+	currentLogLevel = level
+
+	return nil
+}
+
+func logEnabled(level logLevel) bool {
+	return level >= currentLogLevel
+}
+
+func logf(level logLevel, prefix string, format string, args ...any) {
+	if !logEnabled(level) {
+		return
+	}
+
+	logger.Print(prefix + fmt.Sprintf(format, args...))
+}
+
+func debugf(format string, args ...any) {
+	logf(levelDebug, "DEBUG ", format, args...)
+}
+
+func infof(format string, args ...any) {
+	logf(levelInfo, "INFO ", format, args...)
+}
+
+func warnf(format string, args ...any) {
+	logf(levelWarn, "WARN ", format, args...)
+}
+
+func errorf(format string, args ...any) {
+	logf(levelError, "ERROR ", format, args...)
+}
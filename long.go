@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// longCSVHeader is the header of expenses_long.csv, the tidy layout pivot tables expect:
+// one value per deputy, category and month.
+var longCSVHeader = append(expenseDeputyCSVHeader[:len(expenseDeputyCSVHeader):len(expenseDeputyCSVHeader)],
+	"year",
+	"month",
+	"category",
+	"value",
+)
+
+type longKey struct {
+	Year     int
+	Month    int
+	Category string
+}
+
+// expenseYearMonth reads the year and month of an expense document, whose date is 2024-03-15
+// in the API and CEAP dumps and 15/03/2024 on the site and in the Senado dump, or zeros
+// when unknown.
+func expenseYearMonth(date string) (int, int) {
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if len(date) < len(layout) {
+			continue
+		}
+		if t, err := time.Parse(layout, date[:len(layout)]); err == nil {
+			return t.Year(), int(t.Month())
+		}
+	}
+
+	return 0, 0
+}
+
+// longValues sums the quota expenses of deputy by month and category. The months come
+// from -monthly when it was used, otherwise from the dates of the expense documents; the
+// expenses without either are kept with month 0, written as an empty month. A month is
+// of the year of the record, unless a rolling -period recorded another in MonthYears.
+func longValues(d *Deputy) map[longKey]float64 {
+	values := map[longKey]float64{}
+
+	if len(d.MonthlyQuotaDetails) > 0 {
+		for month, details := range d.MonthlyQuotaDetails {
+			year := d.monthYear(month)
+			for _, detail := range details {
+				values[longKey{year, month, detail.Description}] += detail.Value
+			}
+		}
+
+		return values
+	}
+
+	for _, e := range expenseRows(d) {
+		year, month := expenseYearMonth(e.Date)
+		if year == 0 {
+			year = d.Year
+		}
+		values[longKey{year, month, e.Description}] += e.Value
+	}
+
+	return values
+}
+
+func longCSVRecords(deputies []*Deputy) [][]string {
+	var records [][]string
+	for _, d := range deputies {
+		values := longValues(d)
+
+		keys := make([]longKey, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Year != keys[j].Year {
+				return keys[i].Year < keys[j].Year
+			}
+			if keys[i].Month != keys[j].Month {
+				return keys[i].Month < keys[j].Month
+			}
+			return keys[i].Category < keys[j].Category
+		})
+
+		for _, k := range keys {
+			month := ""
+			if k.Month > 0 {
+				month = strconv.Itoa(k.Month)
+			}
+
+			records = append(records, expenseDeputyCSVRecord(d,
+				strconv.Itoa(k.Year),
+				month,
+				k.Category,
+				formatCSVFloat(values[k]),
+			))
+		}
+	}
+
+	return records
+}
+
+func writeLongCSV() error {
+	bytes, err := encodeCSV(longCSVHeader, longCSVRecords(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("expenses_long.csv", bytes)
+}
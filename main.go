@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -13,7 +17,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/m2tx/gocrawler/collector"
 	"github.com/m2tx/gocrawler/queue"
 	"github.com/m2tx/gocrawler/selector"
 	"github.com/m2tx/gocrawler/worker"
@@ -21,12 +24,7 @@ import (
 	"golang.org/x/net/html"
 )
 
-const (
-	bitSize int = 64
-
-	legislatury int = 57
-	year        int = 2024
-)
+const bitSize int = 64
 
 var (
 	deputyRegex *regexp.Regexp
@@ -39,20 +37,89 @@ func init() {
 }
 
 type CostDetail struct {
-	Description string  `json:"description"`
-	Value       float64 `json:"value"`
+	Description    string       `json:"description"`
+	Value          float64      `json:"value"`
+	Date           string       `json:"date,omitempty"`
+	SupplierName   string       `json:"supplierName,omitempty"`
+	SupplierCNPJ   string       `json:"supplierCNPJ,omitempty"`
+	DocumentNumber string       `json:"documentNumber,omitempty"`
+	DocumentURL    string       `json:"documentURL,omitempty"`
+	Documents      []CostDetail `json:"documents,omitempty"`
 }
 
 type Deputy struct {
-	ID                        string       `json:"id"`
-	Name                      string       `json:"name"`
-	PoliticalParty            string       `json:"politicalParty"`
-	State                     string       `json:"state"`
-	Salary                    float64      `json:"salary"`
-	OfficeBudget              float64      `json:"officeBudget"`
-	ParliamentaryQuota        float64      `json:"parliamentaryQuota"`
-	ParliamentaryQuotaDetails []CostDetail `json:"parliamentaryQuotaDetails"`
-	Total                     float64      `json:"total"`
+	ID                        string               `json:"id"`
+	Name                      string               `json:"name"`
+	PoliticalParty            string               `json:"politicalParty"`
+	OriginalParty             string               `json:"originalParty,omitempty"`
+	PartyHistory              []PartyAffiliation   `json:"partyHistory,omitempty"`
+	State                     string               `json:"state"`
+	Legislature               int                  `json:"legislature"`
+	Year                      int                  `json:"year"`
+	Period                    string               `json:"period,omitempty"`
+	Salary                    float64              `json:"salary"`
+	OfficeBudget              float64              `json:"officeBudget"`
+	OfficeBudgetDetails       []CostDetail         `json:"officeBudgetDetails,omitempty"`
+	ParliamentaryQuota        float64              `json:"parliamentaryQuota"`
+	ParliamentaryQuotaDetails []CostDetail         `json:"parliamentaryQuotaDetails,omitempty"`
+	AirTickets                float64              `json:"airTickets"`
+	TravelExpenses            float64              `json:"travelExpenses"`
+	TravelExpensesDetails     []CostDetail         `json:"travelExpensesDetails,omitempty"`
+	HousingAllowance          float64              `json:"housingAllowance"`
+	FunctionalApartment       bool                 `json:"functionalApartment"`
+	Total                     float64              `json:"total"`
+	SourceURL                 string               `json:"sourceURL"`
+	ScrapedAt                 time.Time            `json:"scrapedAt"`
+	MissingFields             []string             `json:"missingFields,omitempty"`
+	Profile                   *DeputyProfile       `json:"profile,omitempty"`
+	Attendance                *Attendance          `json:"attendance,omitempty"`
+	Votes                     *Votes               `json:"votes,omitempty"`
+	Committees                []Committee          `json:"committees,omitempty"`
+	Productivity              *Productivity        `json:"productivity,omitempty"`
+	Campaign                  *Campaign            `json:"campaign,omitempty"`
+	Status                    string               `json:"status,omitempty"`
+	ExercisePeriods           []ExercisePeriod     `json:"exercisePeriods,omitempty"`
+	DaysInOffice              int                  `json:"daysInOffice,omitempty"`
+	CostPerDayInOffice        float64              `json:"costPerDayInOffice,omitempty"`
+	FieldSources              map[string]string    `json:"fieldSources,omitempty"`
+	MonthlyTotals             map[int]float64      `json:"monthlyTotals,omitempty"`
+	MonthlyQuotaDetails       map[int][]CostDetail `json:"monthlyQuotaDetails,omitempty"`
+	MonthYears                map[int]int          `json:"monthYears,omitempty"`
+}
+
+// MarshalJSON leaves out the figures -fields did not select, so that they are not
+// mistaken for figures that were collected and are zero.
+func (d Deputy) MarshalJSON() ([]byte, error) {
+	type deputy Deputy
+	omitted := omittedFigures()
+	if len(omitted) == 0 {
+		return json.Marshal(deputy(d))
+	}
+
+	figure := func(name string, v float64) *float64 {
+		if omitted[name] {
+			return nil
+		}
+		return &v
+	}
+
+	return json.Marshal(struct {
+		deputy
+		Salary             *float64 `json:"salary,omitempty"`
+		OfficeBudget       *float64 `json:"officeBudget,omitempty"`
+		ParliamentaryQuota *float64 `json:"parliamentaryQuota,omitempty"`
+		AirTickets         *float64 `json:"airTickets,omitempty"`
+		TravelExpenses     *float64 `json:"travelExpenses,omitempty"`
+		HousingAllowance   *float64 `json:"housingAllowance,omitempty"`
+	}{
+		deputy:             deputy(d),
+		Salary:             figure("salary", d.Salary),
+		OfficeBudget:       figure("officeBudget", d.OfficeBudget),
+		ParliamentaryQuota: figure("parliamentaryQuota", d.ParliamentaryQuota),
+		AirTickets:         figure("airTickets", d.AirTickets),
+		TravelExpenses:     figure("travelExpenses", d.TravelExpenses),
+		HousingAllowance:   figure("housingAllowance", d.HousingAllowance),
+	})
 }
 
 var (
@@ -62,24 +129,200 @@ var (
 	politicalPartyMap      = map[string][]*Deputy{}
 	politicalPartyTotalMap = map[string]float64{}
 	deputiesArray          = []*Deputy{}
+
+	legislatury = 57
+	year        = 2024
+	years       []int
+	months      []int
+
+	workers       = 20
+	queueSize     = 100
+	flushInterval = 5 * time.Second
+
+	baseURL   = "https://www.camara.leg.br"
+	outputDir = "./tmp"
+
+	perCapita      bool
+	populationFile string
+	chartFormat    = "png"
+	withDetails    = true
+	sortByID       bool
+	byState        bool
+	moneyParser    = "default"
+	listPeriods    bool
+	dryRun         bool
+	verbose        bool
+
+	perRequestTimeout time.Duration
+	retries           int
+
+	errRequestTimeout = errors.New("error.request.timeout")
+
+	states = []string{
+		"AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA",
+		"PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO",
+	}
 )
 
+var commands = map[string]func(ctx context.Context) error{
+	"scrape":   runScrape,
+	"report":   runReport,
+	"validate": runValidate,
+	"serve":    runServe,
+	"ceap":     runCEAP,
+	"senado":   runSenado,
+	"alesp":    runALESP,
+
+	"completion": runCompletion,
+}
+
+// commandArgs holds the positional arguments left after the command flags.
+var commandArgs []string
+
 func main() {
-	ctx := context.Background()
+	command, args := "scrape", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	run, ok := commands[command]
+	if !ok {
+		errorf("unknown command %q, expected one of %s", command, strings.Join(commandNames, ", "))
+		os.Exit(exitUsage)
+	}
+
+	fs := flag.NewFlagSet("godeputy "+command, flag.ExitOnError)
+	registerFlags(fs)
+	registerCommandFlags(command, fs)
+	fs.Parse(args)
+
+	// positional arguments may come before the flags, as in "report html -input deputies.json"
+	for fs.NArg() > 0 {
+		commandArgs = append(commandArgs, fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+
+	if err := loadEnvironment(fs); err != nil {
+		errorf("%v", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := loadConfigFile(fs, configFile, setFlags(fs)["config"]); err != nil {
+		errorf("%v", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := resolveLegislature(setFlags(fs)); err != nil {
+		errorf("%v", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := applyFlags(command); err != nil {
+		errorf("%v", err)
+		os.Exit(exitUsage)
+	}
+	runParameters = flagParameters(fs)
+
+	stopProfiling, err := startProfiling()
+	if err != nil {
+		errorf("%v", err)
+		os.Exit(exitUsage)
+	}
+
+	err = run(context.Background())
+	if publish != nil && exitCode(err) != exitFatal {
+		if publishErr := publishFiles(context.Background()); publishErr != nil {
+			err = publishErr
+		}
+	}
+	if stdoutOutput {
+		err = closeStdout(err)
+	}
+	stopProfiling()
+	if err != nil {
+		errorf("%v", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// runScrape crawls the site, which is what godeputy does when no command is given.
+func runScrape(ctx context.Context) error {
+	if listPeriods {
+		return printPeriods(ctx)
+	}
+
+	if dryRun {
+		return printDeputies(ctx)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("cannot create output directory %q: %v", outputDir, err)
+	}
+
+	if refreshOlderThan > 0 {
+		if err := loadPreviousDeputies(); err != nil {
+			return err
+		}
+	}
+
+	if mergeFiles != "" {
+		return mergeDeputyFiles(strings.Split(mergeFiles, ","))
+	}
+
+	if house != "camara" {
+		return runSource(ctx, sourceFactories[house](), house)
+	}
+
+	if len(period) > 0 {
+		return scrapePeriod(ctx)
+	}
+
+	if len(legislatures) > 1 {
+		return scrapeLegislatures(ctx)
+	}
+
+	if len(years) > 1 {
+		return scrapeYears(ctx)
+	}
+
+	if fullHistory {
+		return scrapeFullHistory(ctx)
+	}
+
+	return scrape(ctx)
+}
+
+// scrape collects the deputies of the current legislature and year and writes every output into outputDir.
+func scrape(ctx context.Context) error {
+	resetResults()
+	resetLayoutCheck()
+	failedDeputies.Store(0)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	if err := openCheckpoint(); err != nil {
+		return err
+	}
+
+	if err := openNDJSON(); err != nil {
+		return err
+	}
 
 	var waitGroup sync.WaitGroup
 
-	queueDeputy = queue.NewQueueTimer[*Deputy](100, 5*time.Second, writeDeputies)
+	queueDeputy = queue.NewQueueTimer[*Deputy](queueSize, flushInterval, writeDeputies)
 	waitGroup.Add(1)
 	go func() {
 		queueDeputy.Start(ctx)
 		waitGroup.Done()
 	}()
 
-	workerDeputy = worker.NewWorkerPool[*Deputy](20, setDeputyDetails)
+	workerDeputy = worker.NewWorkerPool[*Deputy](workers, setDeputyDetails)
 	workerDeputy.Start(ctx)
 
-	getDeputiesCost(ctx)
+	err := getDeputiesCost(ctx)
 
 	workerDeputy.Wait()
 	workerDeputy.Close()
@@ -88,44 +331,284 @@ func main() {
 
 	waitGroup.Wait()
 
-	writePoliticalPartyMap()
+	if err != nil {
+		return err
+	}
+
+	if sortByID {
+		sortDeputies()
+	}
+
+	if err := writePoliticalPartyMap(); err != nil {
+		return err
+	}
+
+	if err := layoutReport(); err != nil {
+		return err
+	}
+
+	// the checkpoint is kept when deputies failed, so -resume only fetches those again
+	if failed := failedDeputies.Load(); failed > 0 {
+		return &partialFailureError{Failed: int(failed)}
+	}
+
+	removeCheckpoint()
+
+	return nil
 }
 
-func writePoliticalPartyMap() {
-	bytes, err := json.MarshalIndent(politicalPartyMap, "", " ")
-	if err != nil {
-		fmt.Println(err)
+// scrapeYears scrapes each year of -ano into its own directory under outputDir and
+// writes the combined outputs of every year into outputDir itself.
+func scrapeYears(ctx context.Context) error {
+	baseDir := outputDir
+	defer func() {
+		outputDir = baseDir
+	}()
+
+	var (
+		all    []*Deputy
+		failed int
+	)
+	for _, y := range years {
+		year = y
+		if legislatureFromYear {
+			legislatury = legislatureOfYear(y)
+		}
+		outputDir = filepath.Join(baseDir, strconv.Itoa(y))
+
+		infof("scraping legislature %d year %d", legislatury, year)
+		var partial *partialFailureError
+		err := scrape(ctx)
+		if errors.As(err, &partial) {
+			failed += partial.Failed
+		} else if err != nil {
+			return err
+		}
+
+		all = append(all, deputiesArray...)
 	}
 
-	err = os.WriteFile("./tmp/political_party.json", bytes, 0644)
-	if err != nil {
-		fmt.Println(err)
+	outputDir = baseDir
+
+	resetResults()
+	aggregateDeputies(all)
+	if sortByID {
+		sortDeputies()
 	}
 
-	bytes, err = json.MarshalIndent(politicalPartyTotalMap, "", " ")
-	if err != nil {
-		fmt.Println(err)
+	if err := writePoliticalPartyMap(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return &partialFailureError{Failed: failed}
+	}
+
+	return nil
+}
+
+func resetResults() {
+	politicalPartyMap = map[string][]*Deputy{}
+	politicalPartyTotalMap = map[string]float64{}
+	deputiesArray = []*Deputy{}
+	outputFiles = nil
+	startedAt = time.Now().UTC()
+}
+
+// sortDeputies makes the output independent of the order in which the workers
+// finished. Map keys are already sorted by encoding/json, so only the slices need
+// stabilizing; the party totals are summed again in that order because floating
+// point addition is not associative.
+func sortDeputies() {
+	sort.SliceStable(deputiesArray, func(i, j int) bool {
+		return lessDeputyID(deputiesArray[i].ID, deputiesArray[j].ID)
+	})
+
+	politicalPartyTotalMap = map[string]float64{}
+	for party, deputies := range politicalPartyMap {
+		sort.SliceStable(deputies, func(i, j int) bool {
+			return lessDeputyID(deputies[i].ID, deputies[j].ID)
+		})
+
+		for _, d := range deputies {
+			politicalPartyTotalMap[party] += d.Total
+		}
+	}
+}
+
+// lessDeputyID orders numeric IDs by value and falls back to string comparison.
+func lessDeputyID(a, b string) bool {
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+
+	return a < b
+}
+
+// writePoliticalPartyMap writes every output of the run, stopping at the first that
+// cannot be written so the run fails instead of publishing an incomplete dataset. The
+// summary, the report and the charts only present the dataset and stay best-effort: one
+// that fails is logged and the run goes on.
+func writePoliticalPartyMap() error {
+	if wantFormat("json") {
+		if err := writeJSONOutputs(); err != nil {
+			return err
+		}
+	}
+
+	if wantFormat("ndjson") {
+		if err := closeNDJSON(); err != nil {
+			return err
+		}
+	}
+
+	writers := []struct {
+		format string
+		write  func() error
+	}{
+		{"csv", writeDeputiesCSV},
+		{"csv", writeCostDetailsCSV},
+		{"csv", writeDataPackage},
+		{"long", writeLongCSV},
+		{"xlsx", writeXLSX},
+		{"parquet", writeParquet},
+		{"avro", writeAvro},
+	}
+	for _, w := range writers {
+		if !wantFormat(w.format) {
+			continue
+		}
+		if err := w.write(); err != nil {
+			return err
+		}
+	}
+
+	reports := []struct {
+		enabled bool
+		write   func() error
+	}{
+		{wantFormat("md"), writeMarkdownSummary},
+		{wantFormat("pdf"), writePDFReport},
+		{wantChart(), writeMapChart},
+		{wantChart(), writeTopDeputiesChart},
+		{wantChart() && categoryCharts, writeCategoryCharts},
+	}
+	for _, r := range reports {
+		if !r.enabled {
+			continue
+		}
+		if err := r.write(); err != nil {
+			errorf("%v", err)
+		}
+	}
+
+	if err := writeOutputTargets(context.Background()); err != nil {
+		return err
+	}
+
+	if err := writeMetadata(); err != nil {
+		return err
+	}
+
+	if err := writeManifest(); err != nil {
+		return err
 	}
 
-	err = os.WriteFile("./tmp/political_party_total.json", bytes, 0644)
+	if archivePath != "" {
+		if err := writeArchive(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSONOutputs() error {
+	bytes, err := encodeOutputJSON(politicalPartyMap)
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+
+	if err := writeOutputFile("political_party.json", bytes); err != nil {
+		return err
 	}
 
-	bytes, err = json.MarshalIndent(deputiesArray, "", " ")
+	bytes, err = encodeOutputJSON(politicalPartyTotalMap)
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+
+	if err := writeOutputFile("political_party_total.json", bytes); err != nil {
+		return err
 	}
 
-	err = os.WriteFile("./tmp/deputies.json", bytes, 0644)
+	bytes, err = encodeOutputJSON(deputiesArray)
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+
+	if validateOutput {
+		if err := validateDeputiesJSON(bytes); err != nil {
+			return err
+		}
 	}
 
-	writeMapPNG()
+	if err := writeOutputFile("deputies.json", bytes); err != nil {
+		return err
+	}
+
+	optional := []struct {
+		enabled bool
+		write   func() error
+	}{
+		{true, writePartyStats},
+		{withPartyHistory, writePartyTotalsByAffiliation},
+		{withCommittees, writeCommitteeTotals},
+		{withProfile, writeDemographicStats},
+		{withCampaign, writeCampaignComparison},
+		{perCapita, writeStatePerCapita},
+	}
+	for _, o := range optional {
+		if !o.enabled {
+			continue
+		}
+		if err := o.write(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasPartyCosts tells whether any party spent anything, which the pie needs to be drawn.
+func hasPartyCosts() bool {
+	for _, total := range politicalPartyTotalMap {
+		if total > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeMapChart writes the pie of the parties, skipped when no party spent anything.
+func writeMapChart() error {
+	if !hasPartyCosts() {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	if err := politicalPartyChart().Render(chartRenderer(), &buffer); err != nil {
+		return fmt.Errorf("error.chart.parties: %v", err)
+	}
+
+	return writeOutputFile("political_party_total."+chartFormat, buffer.Bytes())
 }
 
-func writeMapPNG() {
+// politicalPartyChart is the pie of the nine parties that spent the most, the others summed in a tenth slice.
+func politicalPartyChart() chart.PieChart {
 	var list []struct {
 		Key   string
 		Value float64
@@ -164,7 +647,7 @@ func writeMapPNG() {
 			total = 0
 		} else if len(list)-1 == i {
 			data = append(data, chart.Value{
-				Label: fmt.Sprintf("10 Outros(%.02fm)", total/1000000),
+				Label: fmt.Sprintf("10 %s(%.02fm)", translate("chart.party.others"), total/1000000),
 				Value: total / 1000000,
 				Style: chart.Style{
 					FontColor: chart.ColorBlack,
@@ -176,26 +659,29 @@ func writeMapPNG() {
 		}
 	}
 
-	ch := chart.PieChart{
+	return chart.PieChart{
 		Height: 512,
-		Title:  "Gastos por partido político",
+		Title:  translate("chart.party.title"),
 		Values: data,
 	}
+}
 
-	f, err := os.Create("./tmp/political_party_total.png")
-	if err != nil {
-		return
+func chartRenderer() chart.RendererProvider {
+	if chartFormat == "svg" {
+		return chart.SVG
 	}
-	defer f.Close()
 
-	err = ch.Render(chart.PNG, f)
-	if err != nil {
-		return
-	}
+	return chart.PNG
 }
 
 func writeDeputies(ctx context.Context, deputies []*Deputy) {
-	fmt.Printf("write deputies %d\n", len(deputies))
+	infof("write deputies %d", len(deputies))
+	aggregateDeputies(deputies)
+
+	writeSinks(ctx, deputies)
+}
+
+func aggregateDeputies(deputies []*Deputy) {
 	for _, d := range deputies {
 		deputies := politicalPartyMap[d.PoliticalParty]
 		if deputies == nil {
@@ -209,10 +695,109 @@ func writeDeputies(ctx context.Context, deputies []*Deputy) {
 	}
 }
 
-func getDeputiesCost(ctx context.Context) {
+func getDeputiesCost(ctx context.Context) error {
+	deputies, err := discoverDeputies(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range selectDeputies(deputies) {
+		workerDeputy.Add(d)
+	}
+
+	return nil
+}
+
+// printDeputies prints the deputies that would be scraped without visiting their pages.
+func printDeputies(ctx context.Context) error {
+	deputies, err := discoverDeputies(ctx)
+	if err != nil {
+		return err
+	}
+
+	selected := selectDeputies(deputies)
+	for _, d := range selected {
+		fmt.Printf("%s\t%s\t%s\t%s\n", d.ID, d.Name, d.PoliticalParty, d.State)
+	}
+	fmt.Printf("%d deputies\n", len(selected))
+
+	return nil
+}
+
+// discoverDeputies lists the deputies of the period from the site, or the API with
+// -source api, asking for each state of -uf when it is set.
+func discoverDeputies(ctx context.Context) ([]*Deputy, error) {
+	if sources[0] == "api" {
+		return listAPIDeputies(ctx)
+	}
+
+	if len(ufFilter) > 0 {
+		var lists [][]*Deputy
+		for _, uf := range ufFilter {
+			deputies, err := listDeputies(ctx, listingURL(uf))
+			if err != nil {
+				return nil, err
+			}
+			lists = append(lists, deputies)
+		}
+
+		return dedupeDeputies(lists...), nil
+	}
+
+	deputies, err := listDeputies(ctx, listingURL(""))
+	if err != nil {
+		return nil, err
+	}
+
+	if !byState {
+		return deputies, nil
+	}
+
+	lists := [][]*Deputy{deputies}
+	for _, uf := range states {
+		stateDeputies, err := listDeputies(ctx, listingURL(uf))
+		if err != nil {
+			errorf("%v", err)
+			continue
+		}
+		lists = append(lists, stateDeputies)
+	}
+
+	merged := dedupeDeputies(lists...)
+	if len(merged) != len(deputies) {
+		warnf("the unfiltered listing returned %d deputies but %d were found filtering by state", len(deputies), len(merged))
+	}
+
+	return merged, nil
+}
+
+// dedupeDeputies concatenates the lists keeping the first deputy of each ID.
+func dedupeDeputies(lists ...[]*Deputy) []*Deputy {
+	seen := map[string]bool{}
+
+	var deputies []*Deputy
+	for _, list := range lists {
+		for _, d := range list {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				deputies = append(deputies, d)
+			}
+		}
+	}
+
+	return deputies
+}
+
+func listingURL(uf string) string {
+	return fmt.Sprintf("%s/transparencia/gastos-parlamentares?legislatura=%d&ano=%d&mes=&por=deputado&deputado=&uf=%s&partido=", baseURL, legislatury, year, uf)
+}
+
+func listDeputies(ctx context.Context, url string) ([]*Deputy, error) {
 	attrValue := selector.Attribute("value")
 
-	c := collector.NewWithDefault()
+	var deputies []*Deputy
+
+	c := newCollector(ctx)
 
 	c.OnNode("select#deputado option", func(req *http.Request, resp *http.Response, node *html.Node) error {
 		if node.FirstChild.Type == html.TextNode {
@@ -220,102 +805,250 @@ func getDeputiesCost(ctx context.Context) {
 			if deputyRegex.Match([]byte(data)) {
 				strs := deputyRegex.FindStringSubmatch(data)
 
-				deputy := &Deputy{
+				deputies = append(deputies, &Deputy{
 					ID:             attrValue.Val(node),
 					Name:           strs[1],
 					PoliticalParty: strs[2],
 					State:          strs[3],
-				}
-
-				workerDeputy.Add(deputy)
+				})
 			}
 		}
 
 		return nil
 	})
 
-	err := c.Visit(fmt.Sprintf("https://www.camara.leg.br/transparencia/gastos-parlamentares?legislatura=%d&ano=%d&mes=&por=deputado&deputado=&uf=&partido=", legislatury, year))
-	if err != nil {
-		fmt.Println(err)
-		return
+	if err := c.Visit(url); err != nil {
+		return nil, err
 	}
+
+	return deputies, nil
 }
 
 func setDeputyDetails(ctx context.Context, deputy *Deputy) {
-	c := collector.NewWithDefault()
+	if d, ok := resumedDeputies[deputy.ID]; ok {
+		queueDeputy.Add(d)
+		return
+	}
 
-	c.OnRequest(func(req *http.Request) error {
-		fmt.Println(req.URL)
+	if d, ok := freshDeputy(deputy.ID); ok {
+		queueDeputy.Add(d)
+		return
+	}
 
-		return nil
-	})
+	if err := collectDeputyDetails(ctx, deputy); err != nil {
+		errorf("deputy %s: %v", deputy.ID, err)
+		failedDeputies.Add(1)
+		return
+	}
 
-	c.OnNode("section#verba div.container div.gastos__resumo p.gastos__resumo-texto--destaque", func(req *http.Request, resp *http.Response, node *html.Node) error {
-		data := node.FirstChild.Data
+	enrichDeputy(ctx, deputy)
 
-		strs := realRegex.FindStringSubmatch(data)
+	deputy.Legislature, deputy.Year = legislatury, year
+	deputy.Total = deputyTotal(deputy)
+	prorate(deputy)
+	deputy.ScrapedAt = time.Now().UTC()
 
-		officeBudget, err := parseFloat(strs[0])
-		if err != nil {
-			return err
-		}
+	queueDeputy.Add(deputy)
+}
 
-		deputy.OfficeBudget = officeBudget
+// deputyTotal sums every figure collected for a deputy.
+func deputyTotal(d *Deputy) float64 {
+	return d.Salary + d.OfficeBudget + d.ParliamentaryQuota + d.TravelExpenses + d.HousingAllowance
+}
 
-		return nil
-	})
+// collectDeputyDetails visits the deputy page of the whole year or, when -mes or -monthly
+// is set, of each selected month, adding the monthly figures together.
+func collectDeputyDetails(ctx context.Context, deputy *Deputy) error {
+	selected := months
+	if len(selected) == 0 && monthlyBreakdown {
+		selected = allMonths()
+	}
 
-	c.OnNode("div.remuneracao-viagens div#remuneracao p.remuneracao-viagens__desc", func(req *http.Request, resp *http.Response, node *html.Node) error {
-		data := node.FirstChild.Data
+	if len(selected) == 0 {
+		return visitDeputyDetailsWithRetries(ctx, deputy, 0)
+	}
 
-		strs := realRegex.FindStringSubmatch(data)
+	deputy.Salary, deputy.OfficeBudget, deputy.ParliamentaryQuota, deputy.TravelExpenses = 0, 0, 0, 0
+	deputy.HousingAllowance, deputy.FunctionalApartment, deputy.AirTickets = 0, false, 0
+	deputy.ParliamentaryQuotaDetails, deputy.TravelExpensesDetails, deputy.MissingFields = nil, nil, nil
+	deputy.MonthlyTotals, deputy.MonthlyQuotaDetails = nil, nil
 
-		salary, err := parseFloat(strs[0])
-		if err != nil {
+	var urls []string
+	for _, month := range selected {
+		monthly := &Deputy{ID: deputy.ID}
+		if err := visitDeputyDetailsWithRetries(ctx, monthly, month); err != nil {
 			return err
 		}
 
-		deputy.Salary = salary
+		if monthlyBreakdown {
+			addMonthlyFigures(deputy, month, monthly)
+		}
+		addDeputyFigures(deputy, monthly)
+		urls = append(urls, monthly.SourceURL)
+	}
+	deputy.SourceURL = strings.Join(urls, " ")
 
-		return nil
-	})
+	return nil
+}
 
-	c.OnNode("section#cota table#js-tipo-despesa.js-chart--pie tbody tr", func(req *http.Request, resp *http.Response, node *html.Node) error {
-		query := selector.QueryString("td")
-		nodes := query.Select(node)
+// addDeputyFigures adds the values of src to dst, summing details of the same category.
+func addDeputyFigures(dst, src *Deputy) {
+	dst.Salary += src.Salary
+	dst.OfficeBudget += src.OfficeBudget
+	dst.ParliamentaryQuota += src.ParliamentaryQuota
+	dst.AirTickets += src.AirTickets
+	dst.TravelExpenses += src.TravelExpenses
+	dst.HousingAllowance += src.HousingAllowance
+	dst.FunctionalApartment = dst.FunctionalApartment || src.FunctionalApartment
+
+	dst.ParliamentaryQuotaDetails = addCostDetails(dst.ParliamentaryQuotaDetails, src.ParliamentaryQuotaDetails)
+	dst.TravelExpensesDetails = addCostDetails(dst.TravelExpensesDetails, src.TravelExpensesDetails)
+
+	for _, field := range src.MissingFields {
+		markMissing(dst, field)
+	}
 
-		value, err := parseFloat(nodes[1].FirstChild.Data)
-		if err != nil {
-			return fmt.Errorf("error.cost.details: %v", err)
+	for field, name := range src.FieldSources {
+		addFieldSource(dst, field, name)
+	}
+}
+
+func addCostDetails(dst, src []CostDetail) []CostDetail {
+	for _, detail := range src {
+		found := false
+		for i := range dst {
+			if dst[i].Description == detail.Description {
+				dst[i].Value += detail.Value
+				dst[i].Documents = append(dst[i].Documents, detail.Documents...)
+				found = true
+				break
+			}
 		}
+		if !found {
+			detail.Documents = append([]CostDetail(nil), detail.Documents...)
+			dst = append(dst, detail)
+		}
+	}
 
-		costDetails := CostDetail{
-			Description: nodes[0].FirstChild.Data,
-			Value:       value,
+	return dst
+}
+
+func visitDeputyDetailsWithRetries(ctx context.Context, deputy *Deputy, month int) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		err = visitDeputyDetails(ctx, deputy, month)
+		if !errors.Is(err, errRequestTimeout) {
+			break
 		}
-		deputy.ParliamentaryQuotaDetails = append(deputy.ParliamentaryQuotaDetails, costDetails)
+		warnf("timeout fetching deputy %s (attempt %d)", deputy.ID, attempt+1)
+	}
+
+	return err
+}
+
+func detailURL(id string, month int) string {
+	mes := ""
+	if month > 0 {
+		mes = strconv.Itoa(month)
+	}
+
+	return fmt.Sprintf("%s/transparencia/gastos-parlamentares?legislatura=%d&ano=%d&mes=%s&por=deputado&deputado=%s&uf=&partido=", baseURL, legislatury, year, mes, id)
+}
+
+// visitDeputyDetails fills deputy from its page for month, or the whole year when month is 0.
+func visitDeputyDetails(ctx context.Context, deputy *Deputy, month int) error {
+	if len(sources) > 1 {
+		return visitHybridDeputyDetails(ctx, deputy, month)
+	}
+
+	return visitSource(ctx, sources[0], deputy, month)
+}
+
+func visitSource(ctx context.Context, name string, deputy *Deputy, month int) error {
+	if perRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, perRequestTimeout)
+		defer cancel()
+	}
+
+	if name == "api" {
+		return visitAPIDeputyDetails(ctx, deputy, month)
+	}
+
+	return visitHTMLDeputyDetails(ctx, deputy, month)
+}
+
+func visitHTMLDeputyDetails(ctx context.Context, deputy *Deputy, month int) error {
+	url := detailURL(deputy.ID, month)
+	deputy.SourceURL = url
+	deputy.ParliamentaryQuotaDetails = nil
+	deputy.AirTickets = 0
+	deputy.TravelExpensesDetails = nil
+	deputy.MissingFields = nil
+
+	c := newCollector(ctx)
+
+	c.OnRequest(func(req *http.Request) error {
+		debugf("visit %s", req.URL)
 
 		return nil
 	})
 
-	c.OnNode("div.gastos__resumo div.card-body section p.gastos__resumo-texto--destaque span", func(req *http.Request, resp *http.Response, node *html.Node) error {
-		parliamentaryQuota, err := parseFloat(node.FirstChild.Data)
-		if err != nil {
-			return fmt.Errorf("error.cost.total: %v", err)
+	var page *html.Node
+	c.OnNode("html", func(req *http.Request, resp *http.Response, node *html.Node) error {
+		if resp.Request != nil {
+			deputy.SourceURL = resp.Request.URL.String()
 		}
-
-		deputy.ParliamentaryQuota = parliamentaryQuota
+		page = node
 
 		return nil
 	})
 
-	if err := c.Visit(fmt.Sprintf("https://www.camara.leg.br/transparencia/gastos-parlamentares?legislatura=%d&ano=%d&mes=&por=deputado&deputado=%s&uf=&partido=", legislatury, year, deputy.ID)); err != nil {
-		return
+	extractors := activeExtractors()
+	matches := make([]int, len(extractors))
+	for i, e := range extractors {
+		i, extract := i, e.Extract
+		c.OnNode(e.Query, func(req *http.Request, resp *http.Response, node *html.Node) error {
+			matches[i]++
+			return extract(ctx, deputy, node)
+		})
+	}
+
+	if err := c.Visit(url); err != nil {
+		return visitError(ctx, deputy, err)
 	}
 
-	deputy.Total = deputy.Salary + deputy.OfficeBudget + deputy.ParliamentaryQuota
+	if err := visitQuotaPages(ctx, deputy, quotaTableExtractors(extractors), page); err != nil {
+		return err
+	}
 
-	queueDeputy.Add(deputy)
+	if checkLayout {
+		recordLayout(deputy, page, extractors, matches)
+	}
+
+	if logEnabled(levelDebug) {
+		for i, e := range extractors {
+			if matches[i] == 0 {
+				warnf("selector %q matched no nodes for deputy %s", e.Query, deputy.ID)
+				continue
+			}
+			debugf("selector %q matched %d nodes for deputy %s", e.Query, matches[i], deputy.ID)
+		}
+	}
+
+	return nil
+}
+
+// visitError tells a timeout or a redirect loop apart from other errors of a deputy visit.
+func visitError(ctx context.Context, deputy *Deputy, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%w: deputy %s: %v", errRequestTimeout, deputy.ID, err)
+	}
+	if errors.Is(err, errTooManyRedirects) {
+		return fmt.Errorf("too many redirects fetching deputy %s, possibly session expired: %w", deputy.ID, err)
+	}
+
+	return err
 }
 
 func parseFloat(v string) (value float64, err error) {
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// withMandate fetches the mandate history of every deputy to know who was in office, and for how long.
+	withMandate bool
+
+	// withPartyHistory records the parties a deputy was affiliated to during the legislature.
+	withPartyHistory bool
+)
+
+type ExercisePeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type PartyAffiliation struct {
+	Party string `json:"party"`
+	Since string `json:"since"`
+}
+
+type apiMandateEvent struct {
+	DateTime    string `json:"dataHora"`
+	Situation   string `json:"situacao"`
+	Condition   string `json:"condicaoEleitoral"`
+	Party       string `json:"siglaPartido"`
+	Legislature int    `json:"idLegislatura"`
+}
+
+// fetchHistory reads /deputados/{id}/historico of the API, sorted by date.
+func fetchHistory(ctx context.Context, id string) ([]apiMandateEvent, error) {
+	var events []apiMandateEvent
+	err := getAPIPages(ctx, fmt.Sprintf("%s/deputados/%s/historico", apiURL, id), func(data json.RawMessage) error {
+		var page []apiMandateEvent
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+
+		events = append(events, page...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].DateTime < events[j].DateTime
+	})
+
+	return events, nil
+}
+
+// fetchMandate sets the electoral condition (Titular or Suplente) held in the year and
+// the periods of it the deputy was in exercise.
+func fetchMandate(ctx context.Context, deputy *Deputy) error {
+	events, err := fetchHistory(ctx, deputy.ID)
+	if err != nil {
+		return err
+	}
+
+	setMandate(deputy, events, time.Now().UTC())
+
+	return nil
+}
+
+// fetchPartyHistory records every party change of deputy in the legislature, keeping the
+// party it was elected by as OriginalParty when it is not the current one.
+func fetchPartyHistory(ctx context.Context, deputy *Deputy) error {
+	events, err := fetchHistory(ctx, deputy.ID)
+	if err != nil {
+		return err
+	}
+
+	setPartyHistory(deputy, events)
+
+	return nil
+}
+
+func setPartyHistory(deputy *Deputy, events []apiMandateEvent) {
+	deputy.PartyHistory, deputy.OriginalParty = nil, ""
+
+	for _, e := range events {
+		if e.Legislature != legislatury || e.Party == "" {
+			continue
+		}
+
+		if n := len(deputy.PartyHistory); n > 0 && deputy.PartyHistory[n-1].Party == e.Party {
+			continue
+		}
+
+		since, _, _ := strings.Cut(e.DateTime, "T")
+		deputy.PartyHistory = append(deputy.PartyHistory, PartyAffiliation{Party: e.Party, Since: since})
+	}
+
+	if len(deputy.PartyHistory) > 0 && deputy.PartyHistory[0].Party != deputy.PoliticalParty {
+		deputy.OriginalParty = deputy.PartyHistory[0].Party
+	}
+}
+
+func setMandate(deputy *Deputy, events []apiMandateEvent, now time.Time) {
+	yearStart, yearEnd := mandateYear(now)
+
+	deputy.Status, deputy.ExercisePeriods, deputy.DaysInOffice = "", nil, 0
+
+	var (
+		inOffice bool
+		since    time.Time
+	)
+	closePeriod := func(until time.Time) {
+		addExercisePeriod(deputy, since, until, yearStart, yearEnd)
+	}
+
+	for _, e := range events {
+		at, err := time.Parse("2006-01-02T15:04", e.DateTime)
+		if err != nil {
+			continue
+		}
+		at = at.Truncate(24 * time.Hour)
+		if at.After(yearEnd) {
+			break
+		}
+
+		if e.Condition != "" {
+			deputy.Status = e.Condition
+		}
+
+		exercising := strings.EqualFold(e.Situation, "Exercício")
+		switch {
+		case exercising && !inOffice:
+			inOffice, since = true, at
+		case !exercising && inOffice:
+			inOffice = false
+			closePeriod(at.AddDate(0, 0, -1))
+		}
+	}
+
+	if inOffice {
+		closePeriod(yearEnd)
+	}
+}
+
+// mandateYear returns the first and last day of -ano, the last being today while the
+// year is not over.
+func mandateYear(now time.Time) (time.Time, time.Time) {
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if now.Before(yearEnd) {
+		yearEnd = now.Truncate(24 * time.Hour)
+	}
+
+	return yearStart, yearEnd
+}
+
+// addExercisePeriod records the days from start to end that fall within the year as a
+// period in exercise of deputy.
+func addExercisePeriod(deputy *Deputy, start, end, yearStart, yearEnd time.Time) {
+	if start.Before(yearStart) {
+		start = yearStart
+	}
+	if end.After(yearEnd) {
+		end = yearEnd
+	}
+	if end.Before(start) {
+		return
+	}
+
+	deputy.ExercisePeriods = append(deputy.ExercisePeriods, ExercisePeriod{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+	})
+	deputy.DaysInOffice += int(end.Sub(start).Hours()/24) + 1
+}
+
+// prorate sets the cost per day in office and per proposition once the total of deputy is known.
+func prorate(deputy *Deputy) {
+	if deputy.DaysInOffice > 0 {
+		deputy.CostPerDayInOffice = deputy.Total / float64(deputy.DaysInOffice)
+	}
+	if p := deputy.Productivity; p != nil && p.Propositions > 0 {
+		p.CostPerProposition = deputy.Total / float64(p.Propositions)
+	}
+}
+
+// partyAt returns the party deputy was affiliated to on date, formatted as 2006-01-02.
+func partyAt(deputy *Deputy, date string) string {
+	party := deputy.PoliticalParty
+	for _, a := range deputy.PartyHistory {
+		if a.Since > date {
+			break
+		}
+		party = a.Party
+	}
+
+	return party
+}
+
+// partyTotalsByAffiliation sums the spending of each party attributing every month of a
+// deputy, when -monthly is set, to the party it was affiliated to in the middle of that month.
+func partyTotalsByAffiliation(deputies []*Deputy) map[string]float64 {
+	totals := map[string]float64{}
+	for _, d := range deputies {
+		if len(d.PartyHistory) == 0 || len(d.MonthlyTotals) == 0 {
+			totals[d.PoliticalParty] += d.Total
+			continue
+		}
+
+		for month, total := range d.MonthlyTotals {
+			totals[partyAt(d, fmt.Sprintf("%04d-%02d-15", d.monthYear(month), month))] += total
+		}
+	}
+
+	return totals
+}
+
+func writePartyTotalsByAffiliation() error {
+	bytes, err := encodeOutputJSON(partyTotalsByAffiliation(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("political_party_total_by_affiliation.json", bytes)
+}
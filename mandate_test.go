@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMandate(t *testing.T) {
+	events := []apiMandateEvent{
+		{DateTime: "2023-02-01T00:00", Situation: "Suplência", Condition: "Suplente"},
+		{DateTime: "2024-03-10T00:00", Situation: "Exercício", Condition: "Suplente"},
+		{DateTime: "2024-07-01T10:30", Situation: "Suplência", Condition: "Suplente"},
+		{DateTime: "2024-10-01T00:00", Situation: "Exercício", Condition: "Suplente"},
+	}
+
+	deputy := &Deputy{ID: "1", Total: 41000}
+	setMandate(deputy, events, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	prorate(deputy)
+
+	assert.Equal(t, "Suplente", deputy.Status)
+	assert.Equal(t, []ExercisePeriod{
+		{Start: "2024-03-10", End: "2024-06-30"},
+		{Start: "2024-10-01", End: "2024-12-31"},
+	}, deputy.ExercisePeriods)
+	assert.Equal(t, 205, deputy.DaysInOffice)
+	assert.Equal(t, 200.0, deputy.CostPerDayInOffice)
+}
+
+func TestSetPartyHistory(t *testing.T) {
+	events := []apiMandateEvent{
+		{DateTime: "2019-02-01T00:00", Party: "PSL", Legislature: 56},
+		{DateTime: "2023-02-01T00:00", Party: "PSC", Legislature: 57},
+		{DateTime: "2023-05-10T00:00", Party: "PSC", Legislature: 57},
+		{DateTime: "2024-03-07T12:00", Party: "PL", Legislature: 57},
+	}
+
+	deputy := &Deputy{ID: "1", PoliticalParty: "PL"}
+	setPartyHistory(deputy, events)
+
+	assert.Equal(t, []PartyAffiliation{
+		{Party: "PSC", Since: "2023-02-01"},
+		{Party: "PL", Since: "2024-03-07"},
+	}, deputy.PartyHistory)
+	assert.Equal(t, "PSC", deputy.OriginalParty)
+}
+
+func TestPartyTotalsByAffiliation(t *testing.T) {
+	switched := &Deputy{
+		ID:             "1",
+		PoliticalParty: "PL",
+		Year:           2024,
+		Total:          300,
+		PartyHistory: []PartyAffiliation{
+			{Party: "PSC", Since: "2023-02-01"},
+			{Party: "PL", Since: "2024-03-07"},
+		},
+		MonthlyTotals: map[int]float64{1: 100, 2: 100, 3: 100},
+	}
+	other := &Deputy{ID: "2", PoliticalParty: "PT", Total: 50}
+
+	assert.Equal(t, map[string]float64{"PSC": 200, "PL": 100, "PT": 50}, partyTotalsByAffiliation([]*Deputy{switched, other}))
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manifest lists every file of a run with what a consumer needs to check that a
+// published dataset is complete and intact, and the parameters it was produced with.
+type Manifest struct {
+	SchemaVersion string                   `json:"schemaVersion"`
+	GeneratedAt   time.Time                `json:"generatedAt"`
+	Run           RunMetadata              `json:"run"`
+	Parameters    map[string]string        `json:"parameters"`
+	Files         map[string]ManifestEntry `json:"files"`
+}
+
+type ManifestEntry struct {
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+	Records   *int      `json:"records,omitempty"`
+}
+
+// runParameters are the flags given to the run, from the command line, the environment
+// or the config file.
+var runParameters = map[string]string{}
+
+// secretFlags are recorded in the manifest without their value.
+var secretFlags = map[string]bool{
+	"postgres-dsn": true,
+	"sheet-url":    true,
+}
+
+// funcValue is the flag.Value of funcFlag, which unlike the one of flag.Func prints the
+// text it was given, so the manifest records filters such as -ano and -partido.
+type funcValue struct {
+	set   func(string) error
+	given []string
+}
+
+func (v *funcValue) Set(s string) error {
+	v.given = append(v.given, s)
+	return v.set(s)
+}
+
+func (v *funcValue) String() string {
+	if v == nil {
+		return ""
+	}
+
+	return strings.Join(v.given, ",")
+}
+
+// funcFlag defines a flag parsed by fn, as fs.Func does.
+func funcFlag(fs *flag.FlagSet, name, usage string, fn func(string) error) {
+	fs.Var(&funcValue{set: fn}, name, usage)
+}
+
+func flagParameters(fs *flag.FlagSet) map[string]string {
+	parameters := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		v := f.Value.String()
+		if secretFlags[f.Name] {
+			v = "REDACTED"
+		}
+		parameters[f.Name] = v
+	})
+
+	// -output is repeatable and kept apart from the other flags
+	var outputs []string
+	for _, o := range outputTargets {
+		target := o.Target
+		if o.Kind == "postgres" {
+			target = "REDACTED"
+		}
+		outputs = append(outputs, o.Kind+"="+target)
+	}
+	if len(outputs) > 0 {
+		parameters["output"] = strings.Join(outputs, ",")
+	}
+
+	return parameters
+}
+
+// outputFiles holds the names of the files written to outputDir during this run.
+var outputFiles []string
+
+// runFiles holds the path of every file the process wrote, across the directories of
+// the years and periods a run is split into, for -publish.
+var runFiles []string
+
+func writeOutputFile(name string, data []byte) error {
+	name = outputName(name)
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzipBytes(data)
+		if err != nil {
+			return err
+		}
+		data = gz
+	}
+
+	path := filepath.Join(outputDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+
+	outputFiles = append(outputFiles, name)
+	runFiles = append(runFiles, path)
+
+	return nil
+}
+
+func fileManifestEntry(name string) (ManifestEntry, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	entry := ManifestEntry{
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      info.Size(),
+		Timestamp: info.ModTime().UTC(),
+	}
+
+	if records, ok := countRecords(name); ok {
+		entry.Records = &records
+	}
+
+	return entry, nil
+}
+
+// countRecords counts the rows of a CSV file without its header, the lines of an NDJSON
+// file and the data of a JSON file: the elements of a list, or the entries of an object
+// that maps keys to records, as the parties of political_party.json.
+func countRecords(name string) (int, bool) {
+	ext := filepath.Ext(strings.TrimSuffix(name, ".gz"))
+	if ext != ".csv" && ext != ".ndjson" && ext != ".json" {
+		return 0, false
+	}
+
+	data, err := readOutputFile(name)
+	if err != nil {
+		warnf("count records of %s: %v", name, err)
+		return 0, false
+	}
+
+	switch ext {
+	case ".csv":
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+
+		rows := 0
+		for {
+			if _, err := r.Read(); err != nil {
+				break
+			}
+			rows++
+		}
+		if rows == 0 {
+			return 0, true
+		}
+
+		return rows - 1, true
+
+	case ".ndjson":
+		lines := 0
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 64*1024*1024)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+				lines++
+			}
+		}
+
+		return lines, true
+	}
+
+	var v any
+	if err := decodeOutputJSON(data, &v); err != nil {
+		return 0, false
+	}
+
+	switch v := v.(type) {
+	case []any:
+		return len(v), true
+	case map[string]any:
+		for _, value := range v {
+			switch value.(type) {
+			case []any, float64:
+			default:
+				return 0, false
+			}
+		}
+		return len(v), true
+	}
+
+	return 0, false
+}
+
+// manifestName is the name of the manifest of the run, after -name-template.
+func manifestName() string {
+	return templateName("manifest.json")
+}
+
+func writeManifest() error {
+	sort.Strings(outputFiles)
+
+	manifest := Manifest{
+		SchemaVersion: outputSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Run:           runMetadata(),
+		Parameters:    runParameters,
+		Files:         map[string]ManifestEntry{},
+	}
+	for _, name := range outputFiles {
+		entry, err := fileManifestEntry(filepath.Join(outputDir, name))
+		if err != nil {
+			return err
+		}
+		manifest.Files[name] = entry
+	}
+
+	bytes, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(outputDir, manifestName())
+	if err := writeFileAtomic(path, bytes); err != nil {
+		return err
+	}
+	runFiles = append(runFiles, path)
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+)
+
+var mergeFiles string
+
+// mergeDeputies dedupes deputies by ID, keeping the most recently scraped record.
+func mergeDeputies(lists ...[]*Deputy) []*Deputy {
+	merged := map[string]*Deputy{}
+	for _, deputies := range lists {
+		for _, d := range deputies {
+			if current, ok := merged[d.ID]; !ok || d.ScrapedAt.After(current.ScrapedAt) {
+				merged[d.ID] = d
+			}
+		}
+	}
+
+	deputies := make([]*Deputy, 0, len(merged))
+	for _, d := range merged {
+		deputies = append(deputies, d)
+	}
+
+	return deputies
+}
+
+// mergeDeputyFiles rebuilds every output from previously written deputies.json files.
+func mergeDeputyFiles(names []string) error {
+	var lists [][]*Deputy
+	for _, name := range names {
+		deputies, err := loadDeputiesFile(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		lists = append(lists, deputies)
+	}
+
+	resetResults()
+	aggregateDeputies(mergeDeputies(lists...))
+	sortDeputies()
+
+	return writePoliticalPartyMap()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type RunMetadata struct {
+	Legislature int       `json:"legislature"`
+	Year        int       `json:"year"`
+	Years       []int     `json:"years,omitempty"`
+	Months      []int     `json:"months,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	Deputies    int       `json:"deputies"`
+	Fields      []string  `json:"fields"`
+}
+
+var startedAt = time.Now().UTC()
+
+// reportMetadata is the metadata of the run the report command renders, read from the
+// metadata.json next to its deputies.json; nil when the reports are written by the run.
+var reportMetadata *RunMetadata
+
+func runMetadata() RunMetadata {
+	return RunMetadata{
+		Legislature: legislatury,
+		Year:        year,
+		Years:       years,
+		Months:      months,
+		StartedAt:   startedAt,
+		FinishedAt:  time.Now().UTC(),
+		Deputies:    len(deputiesArray),
+		Fields:      collectedFields(),
+	}
+}
+
+// summaryMetadata is the metadata shown in summary.md: that of the source run for the
+// report command, so the summary describes the data and not the invocation rendering it.
+func summaryMetadata() RunMetadata {
+	if reportMetadata != nil {
+		return *reportMetadata
+	}
+
+	return runMetadata()
+}
+
+// loadReportMetadata reads the metadata.json written along deputiesFile, named as it was
+// by -name-template. Without one the legislature, year and count come from the deputies.
+func loadReportMetadata(deputiesFile string, deputies []*Deputy) error {
+	dir, base := filepath.Split(deputiesFile)
+	name := filepath.Join(dir, strings.Replace(strings.TrimSuffix(base, ".gz"), "deputies", "metadata", 1))
+
+	bytes, err := readOutputFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		warnf("no %s next to %s, the summary has no run metadata", name, deputiesFile)
+
+		reportMetadata = &RunMetadata{Deputies: len(deputies)}
+		if len(deputies) > 0 {
+			reportMetadata.Legislature, reportMetadata.Year = deputies[0].Legislature, deputies[0].Year
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var metadata RunMetadata
+	if err := decodeOutputJSON(bytes, &metadata); err != nil {
+		return fmt.Errorf("error.metadata.file: %s: %v", name, err)
+	}
+	reportMetadata = &metadata
+
+	return nil
+}
+
+func writeMetadata() error {
+	bytes, err := encodeOutputJSON(runMetadata())
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("metadata.json", bytes)
+}
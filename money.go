@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// parseMoney converts a value scraped from the site into a float. It points to
+// parseFloat unless another parser is selected with -money-parser.
+var parseMoney = parseFloat
+
+var moneyParsers = map[string]func(string) (float64, error){
+	"default": parseFloat,
+	"x-text":  newLocaleMoneyParser(language.BrazilianPortuguese),
+}
+
+// newLocaleMoneyParser returns a parser that uses the group and decimal separators
+// x/text formats numbers with for tag, and validates digit grouping instead of guessing.
+func newLocaleMoneyParser(tag language.Tag) func(string) (float64, error) {
+	sample := []rune(message.NewPrinter(tag).Sprintf("%.1f", 1000.5))
+	group, decimal := sample[1], sample[len(sample)-2]
+
+	return func(v string) (float64, error) {
+		var (
+			digits   strings.Builder
+			integer  []string
+			fraction string
+			seenDec  bool
+			negative bool
+		)
+
+		for _, r := range v {
+			switch {
+			case unicode.IsDigit(r):
+				digits.WriteRune(r)
+			case r == group && !seenDec:
+				integer = append(integer, digits.String())
+				digits.Reset()
+			case r == decimal && !seenDec:
+				integer = append(integer, digits.String())
+				digits.Reset()
+				seenDec = true
+			case r == '-' || r == '−' || r == '(':
+				negative = true
+			case r == ')' || unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.Is(unicode.Sc, r):
+			default:
+				return 0, fmt.Errorf("error.money.invalid: %q", v)
+			}
+		}
+
+		if seenDec {
+			fraction = digits.String()
+		} else {
+			integer = append(integer, digits.String())
+		}
+
+		for i, g := range integer {
+			if (i == 0 && g == "" && len(integer) > 1) || (i > 0 && len(g) != 3) {
+				return 0, fmt.Errorf("error.money.grouping: %q", v)
+			}
+		}
+
+		var number strings.Builder
+		if negative {
+			number.WriteByte('-')
+		}
+		number.WriteString(strings.Join(integer, ""))
+		if fraction != "" {
+			number.WriteByte('.')
+			number.WriteString(fraction)
+		}
+
+		return strconv.ParseFloat(number.String(), bitSize)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyParsers(t *testing.T) {
+	tests := map[string]float64{
+		"R$ 41.650,92":    41650.92,
+		"R$ 1.338.571,75": 1338571.75,
+		"245.310,77":      245310.77,
+		"6.000,00":        6000,
+		"12,5":            12.5,
+		"120":             120,
+		"1.234":           1234,
+	}
+
+	for name, parse := range moneyParsers {
+		for value, want := range tests {
+			got, err := parse(value)
+			if assert.NoError(t, err, "%s(%q)", name, value) {
+				assert.InDelta(t, want, got, 1e-9, "%s(%q)", name, value)
+			}
+		}
+	}
+}
+
+func TestXTextMoneyParserEdgeCases(t *testing.T) {
+	parse := moneyParsers["x-text"]
+
+	got, err := parse("R$\u00a01.234,56")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1234.56, got, 1e-9)
+
+	got, err = parse("-R$ 12,00")
+	assert.NoError(t, err)
+	assert.InDelta(t, -12, got, 1e-9)
+
+	_, err = parse("1.23,45")
+	assert.Error(t, err)
+
+	_, err = parseFloat("R$\u00a01.234,56")
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package main
+
+// monthlyBreakdown visits every month of the year for each deputy, keeping the figures
+// of each month next to the yearly ones.
+var monthlyBreakdown bool
+
+func allMonths() []int {
+	months := make([]int, 12)
+	for i := range months {
+		months[i] = i + 1
+	}
+
+	return months
+}
+
+// addMonthlyFigures records the total and quota details of one month of deputy.
+func addMonthlyFigures(deputy *Deputy, month int, monthly *Deputy) {
+	if deputy.MonthlyTotals == nil {
+		deputy.MonthlyTotals = map[int]float64{}
+	}
+	deputy.MonthlyTotals[month] = deputyTotal(monthly)
+
+	if len(monthly.ParliamentaryQuotaDetails) == 0 {
+		return
+	}
+
+	if deputy.MonthlyQuotaDetails == nil {
+		deputy.MonthlyQuotaDetails = map[int][]CostDetail{}
+	}
+	deputy.MonthlyQuotaDetails[month] = append([]CostDetail(nil), monthly.ParliamentaryQuotaDetails...)
+}
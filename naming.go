@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nameTemplate names the files written to outputDir, so that runs sharing a directory
+// do not overwrite each other, e.g. {name}_{legislatura}_{ano}_{date} writes
+// deputies_57_2024_2024-06-30.json. The extension of each file is kept.
+var nameTemplate = "{name}"
+
+var namePlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// fixedNames are the files consumers look up by their name, written as they are
+// whatever -name-template and -compress say.
+var fixedNames = map[string]bool{
+	"datapackage.json": true,
+}
+
+// nameFields are the placeholders of -name-template.
+var nameFields = map[string]func(name string) string{
+	"name":        func(name string) string { return name },
+	"legislatura": func(string) string { return strconv.Itoa(legislatury) },
+	"ano":         func(string) string { return strconv.Itoa(year) },
+	"date":        func(string) string { return startedAt.Format("2006-01-02") },
+	"time":        func(string) string { return startedAt.Format("150405") },
+}
+
+func parseNameTemplate(v string) error {
+	if !strings.Contains(v, "{name}") {
+		return fmt.Errorf("invalid name template %q, it must contain {name}", v)
+	}
+	if strings.ContainsAny(v, `/\`) {
+		return fmt.Errorf("invalid name template %q, it must not contain a path separator", v)
+	}
+
+	for _, placeholder := range namePlaceholder.FindAllString(v, -1) {
+		if _, ok := nameFields[strings.Trim(placeholder, "{}")]; !ok {
+			return fmt.Errorf("unknown placeholder %s in name template, expected {name}, {legislatura}, {ano}, {date} or {time}", placeholder)
+		}
+	}
+
+	nameTemplate = v
+
+	return nil
+}
+
+// outputName is the name a file called name is written under in outputDir, after
+// -name-template and -compress. Files under a subdirectory, as the category charts,
+// keep it.
+func outputName(name string) string {
+	if fixedNames[name] {
+		return name
+	}
+
+	name = templateName(name)
+	if compressOutput && compressedExtensions[filepath.Ext(name)] {
+		name += ".gz"
+	}
+
+	return name
+}
+
+// templateName applies -name-template to name, for the files -compress leaves alone
+// such as the manifest and the checkpoint.
+func templateName(name string) string {
+	if fixedNames[name] {
+		return name
+	}
+
+	dir, base := filepath.Split(name)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	stem = namePlaceholder.ReplaceAllStringFunc(nameTemplate, func(placeholder string) string {
+		return nameFields[strings.Trim(placeholder, "{}")](stem)
+	})
+
+	return dir + stem + ext
+}
+
+// namePatterns match the placeholders that depend on the clock, which a later run
+// cannot rebuild.
+var namePatterns = map[string]string{
+	"date": "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]",
+	"time": "[0-9][0-9][0-9][0-9][0-9][0-9]",
+}
+
+// previousOutputPath finds in outputDir the newest file a previous run wrote as name,
+// compressed or not, whatever {date} and {time} it was named after. Without a match it
+// is the path this run would write name to.
+func previousOutputPath(name string) string {
+	dir, base := filepath.Split(name)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	var pattern strings.Builder
+	last := 0
+	for _, loc := range namePlaceholder.FindAllStringIndex(nameTemplate, -1) {
+		pattern.WriteString(globEscape(nameTemplate[last:loc[0]]))
+
+		field := nameTemplate[loc[0]+1 : loc[1]-1]
+		if p, ok := namePatterns[field]; ok {
+			pattern.WriteString(p)
+		} else {
+			pattern.WriteString(globEscape(nameFields[field](stem)))
+		}
+		last = loc[1]
+	}
+	pattern.WriteString(globEscape(nameTemplate[last:]))
+
+	glob := filepath.Join(outputDir, globEscape(dir)+pattern.String()+globEscape(ext))
+	matches, _ := filepath.Glob(glob)
+	compressed, _ := filepath.Glob(glob + ".gz")
+
+	newest, newestTime := "", time.Time{}
+	for _, match := range append(matches, compressed...) {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = match, info.ModTime()
+		}
+	}
+
+	if newest == "" {
+		return filepath.Join(outputDir, outputName(name))
+	}
+
+	return newest
+}
+
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
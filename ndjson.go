@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ndjsonFile receives every deputy as soon as its batch is flushed from the queue, one JSON
+// object per line, so consumers can stream it and a crashed run keeps what it scraped.
+const ndjsonFile = "deputies.ndjson"
+
+func ndjsonPath() string {
+	return filepath.Join(outputDir, outputName(ndjsonFile))
+}
+
+// openNDJSON discards the deputies.ndjson of a previous run, as the sink only appends to
+// it, or moves it to deputies.ndjson.bak with -backup. With -o - the deputies go straight
+// to stdout and there is no file.
+func openNDJSON() error {
+	if !wantFormat("ndjson") || stdoutOutput {
+		return nil
+	}
+
+	if backupOutputs {
+		if err := os.Rename(ndjsonPath(), ndjsonPath()+".bak"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := os.Remove(ndjsonPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// writeNDJSON appends the flushed deputies to deputies.ndjson, or to stdout with -o -, in
+// the order they were flushed. The batch is encoded first and written at once, so a retry
+// of the sink does not repeat half of it.
+func writeNDJSON(ctx context.Context, deputies []*Deputy) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, d := range deputies {
+		if err := encoder.Encode(d); err != nil {
+			return err
+		}
+	}
+
+	if stdoutOutput {
+		_, err := os.Stdout.Write(buffer.Bytes())
+		return err
+	}
+
+	f, err := os.OpenFile(ndjsonPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// closeNDJSON lists deputies.ndjson among the files of the run once the queue is drained.
+func closeNDJSON() error {
+	if stdoutOutput {
+		return nil
+	}
+
+	if _, err := os.Stat(ndjsonPath()); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	outputFiles = append(outputFiles, outputName(ndjsonFile))
+	runFiles = append(runFiles, ndjsonPath())
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// outputTarget is a destination given with -output as kind=target, e.g. sqlite=./deputies.db.
+type outputTarget struct {
+	Kind   string
+	Target string
+}
+
+var (
+	outputTargets []outputTarget
+
+	// outputWriters write every deputy of the run to a target of their kind once the
+	// run is over, next to the files of outputDir.
+	outputWriters = map[string]func(ctx context.Context, target string, deputies []*Deputy) error{
+		"sqlite":   writeSQLite,
+		"postgres": writePostgres,
+		"gsheet":   writeGoogleSheet,
+	}
+)
+
+func outputKinds() []string {
+	var kinds []string
+	for kind := range outputWriters {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+func parseOutput(v string) error {
+	kind, target, ok := strings.Cut(v, "=")
+	kind, target = strings.TrimSpace(kind), strings.TrimSpace(target)
+	if !ok || target == "" {
+		return fmt.Errorf("invalid output %q, expected kind=target, e.g. sqlite=./deputies.db", v)
+	}
+
+	if _, ok := outputWriters[kind]; !ok {
+		return fmt.Errorf("unknown output %q, expected one of %s", kind, strings.Join(outputKinds(), ", "))
+	}
+
+	outputTargets = append(outputTargets, outputTarget{Kind: kind, Target: target})
+
+	return nil
+}
+
+// writeOutputTargets writes the deputies of the run to every -output, trying the others
+// when one fails and returning every failure.
+func writeOutputTargets(ctx context.Context) error {
+	// the rolling records of -period add up several years, which the tables keyed by
+	// legislature, year and ID would take for the latest one; each year of the period
+	// was already written by its own run
+	if len(outputTargets) > 0 && len(deputiesArray) > 0 && deputiesArray[0].Period != "" {
+		infof("skipping -output for the rolling period %s", deputiesArray[0].Period)
+		return nil
+	}
+
+	var errs []error
+	for _, o := range outputTargets {
+		if err := outputWriters[o.Kind](ctx, o.Target, deputiesArray); err != nil {
+			errs = append(errs, fmt.Errorf("output %s: %v", o.Kind, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
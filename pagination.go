@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+// quotaPageQuery matches the pagination links of the quota table, which the site splits
+// over several pages for deputies with many expense categories.
+var quotaPageQuery selector.QueryString = "section#cota nav.pagination a"
+
+// maxQuotaPages stops following the quota table pagination of a single deputy page.
+const maxQuotaPages = 50
+
+// nextQuotaPage returns the absolute URL of the link marked rel="next" in the pagination
+// of the quota table of page, or "" on its last page.
+func nextQuotaPage(pageURL string, page *html.Node) string {
+	return nextPage(quotaPageQuery, pageURL, page)
+}
+
+// nextPage returns the absolute URL of the link marked rel="next" among the links of page
+// matching query, or "" when there is none.
+func nextPage(query selector.QueryString, pageURL string, page *html.Node) string {
+	if page == nil {
+		return ""
+	}
+
+	attrRel, attrHref := selector.Attribute("rel"), selector.Attribute("href")
+	for _, link := range query.Select(page) {
+		if strings.EqualFold(attrRel.Val(link), "next") {
+			return resolveLink(pageURL, attrHref.Val(link))
+		}
+	}
+
+	return ""
+}
+
+// resolveLink turns the href of a link found on pageURL into an absolute URL, or ""
+// when it is empty or only points to an anchor of the same page.
+func resolveLink(pageURL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	link, err := base.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	return link.String()
+}
+
+// quotaTableExtractors returns the extractors reading the rows of the quota table.
+func quotaTableExtractors(extractors []deputyExtractor) []deputyExtractor {
+	var table []deputyExtractor
+	for _, e := range extractors {
+		if e.Name == "details" || e.Name == "tickets" {
+			table = append(table, e)
+		}
+	}
+
+	return table
+}
+
+// visitQuotaPages follows the pagination of the quota table from the first page of a
+// deputy, running the table extractors over the rows of each following page. The other
+// figures are only shown on the first page.
+func visitQuotaPages(ctx context.Context, deputy *Deputy, extractors []deputyExtractor, first *html.Node) error {
+	if len(extractors) == 0 {
+		return nil
+	}
+
+	documents := false
+	for _, e := range extractors {
+		documents = documents || withDocuments && e.Name == "details"
+	}
+
+	if documents {
+		if err := visitQuotaDocuments(ctx, deputy, deputy.SourceURL, first); err != nil {
+			return err
+		}
+	}
+
+	seen := map[string]bool{deputy.SourceURL: true}
+
+	next := nextQuotaPage(deputy.SourceURL, first)
+	for pages := 1; next != "" && !seen[next]; pages++ {
+		if pages == maxQuotaPages {
+			warnf("quota table of deputy %s has more than %d pages, the rest is ignored", deputy.ID, maxQuotaPages)
+			break
+		}
+		seen[next] = true
+
+		var page *html.Node
+		c := newCollector(ctx)
+
+		c.OnRequest(func(req *http.Request) error {
+			debugf("visit %s", req.URL)
+
+			return nil
+		})
+
+		c.OnNode("html", func(req *http.Request, resp *http.Response, node *html.Node) error {
+			page = node
+
+			return nil
+		})
+
+		for _, e := range extractors {
+			extract := e.Extract
+			c.OnNode(e.Query, func(req *http.Request, resp *http.Response, node *html.Node) error {
+				return extract(ctx, deputy, node)
+			})
+		}
+
+		if err := c.Visit(next); err != nil {
+			return visitError(ctx, deputy, err)
+		}
+
+		if documents {
+			if err := visitQuotaDocuments(ctx, deputy, next, page); err != nil {
+				return err
+			}
+		}
+
+		next = nextQuotaPage(next, page)
+	}
+
+	return nil
+}
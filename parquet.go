@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var (
+	deputyParquetSchema  = parquetSchema("deputy", deputyColumns)
+	expenseParquetSchema = parquetSchema("expense", expenseColumns)
+)
+
+// parquetDictColumns are the columns with few distinct values, dictionary encoded.
+var parquetDictColumns = map[string]bool{
+	"political_party": true,
+	"state":           true,
+	"category":        true,
+}
+
+// parquetNode is the parquet node of a column. Parquet orders the columns of a group by name.
+func parquetNode(c tableColumn) parquet.Node {
+	var node parquet.Node
+	switch c.Type {
+	case columnString:
+		node = parquet.String()
+		if parquetDictColumns[c.Name] {
+			node = parquet.Encoded(node, &parquet.RLEDictionary)
+		}
+	case columnInt:
+		node = parquet.Int(32)
+	case columnMoney:
+		node = parquet.Leaf(parquet.DoubleType)
+	case columnBool:
+		node = parquet.Leaf(parquet.BooleanType)
+	case columnTime:
+		node = parquet.Timestamp(parquet.Millisecond)
+	}
+
+	if c.Optional {
+		return parquet.Optional(node)
+	}
+
+	return node
+}
+
+func parquetSchema(name string, columns []tableColumn) *parquet.Schema {
+	group := parquet.Group{}
+	for _, c := range columns {
+		group[c.Name] = parquetNode(c)
+	}
+
+	return parquet.NewSchema(name, group)
+}
+
+// parquetValue converts a value of a table row into what is written in its column.
+func parquetValue(c tableColumn, v any) any {
+	switch {
+	case c.Type == columnInt:
+		return int32(v.(int))
+	case c.Optional && v == "":
+		return nil
+	}
+
+	return v
+}
+
+// deputyParquetRows are the rows of deputies.parquet.
+func deputyParquetRows(deputies []*Deputy) []map[string]any {
+	rows := make([]map[string]any, 0, len(deputies))
+	for _, d := range deputies {
+		rows = append(rows, tableRecord(deputyColumns, deputyRow(d), parquetValue))
+	}
+
+	return rows
+}
+
+// expenseParquetRows are the rows of expenses.parquet, one per quota category or, with
+// -documents, per expense document.
+func expenseParquetRows(deputies []*Deputy) []map[string]any {
+	var rows []map[string]any
+	for _, d := range deputies {
+		for _, e := range expenseRows(d) {
+			rows = append(rows, tableRecord(expenseColumns, expenseRow(d, e), parquetValue))
+		}
+	}
+
+	return rows
+}
+
+func encodeParquet(schema *parquet.Schema, rows []map[string]any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := parquet.Write(&buffer, rows, schema); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeParquet() error {
+	bytes, err := encodeParquet(deputyParquetSchema, deputyParquetRows(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutputFile("deputies.parquet", bytes); err != nil {
+		return err
+	}
+
+	bytes, err = encodeParquet(expenseParquetSchema, expenseParquetRows(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("expenses.parquet", bytes)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+	chart "github.com/wcharczuk/go-chart"
+)
+
+const (
+	pdfLineHeight = 6
+	pdfChartWidth = 180
+)
+
+// pdfColumn is a column of a table of the PDF report, with its width in millimeters.
+type pdfColumn struct {
+	Title string
+	Width float64
+	Align string
+}
+
+type pdfReport struct {
+	*fpdf.Fpdf
+	tr func(string) string
+}
+
+// encodePDFReport renders the same summary as summary.md on A4 pages, with the party and
+// category charts, so it can be attached to an email or printed.
+func encodePDFReport(summary reportSummary) ([]byte, error) {
+	summary = summary.limit(summaryTop)
+
+	pdf := &pdfReport{Fpdf: fpdf.New("P", "mm", "A4", "")}
+	// the core fonts are encoded as cp1252, which covers the accents of Portuguese
+	pdf.tr = pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(translate("report.title"), true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, pdfLineHeight, fmt.Sprintf("%d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, pdf.tr(translate("report.title")), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, pdfLineHeight, pdf.tr(fmt.Sprintf("%s %d, %d · %d %s · %s %s",
+		translate("report.legislature"), summary.Legislature, summary.Year,
+		summary.Deputies, translate("report.deputies"),
+		translate("report.total"), formatMoney(summary.Total))), "", 1, "L", false, 0, "")
+
+	pdf.heading(translate("report.parties"))
+	if hasPartyCosts() {
+		pdf.chart("parties", politicalPartyChart())
+	}
+
+	var rows [][]string
+	for _, p := range summary.Parties {
+		rows = append(rows, []string{p.Name, strconv.Itoa(p.Count), formatMoney(p.Total), formatMoney(p.Mean)})
+	}
+	pdf.table([]pdfColumn{
+		{translate("report.party"), 60, "L"},
+		{translate("report.deputies"), 30, "R"},
+		{translate("report.total"), 50, "R"},
+		{translate("report.mean"), 40, "R"},
+	}, rows)
+
+	pdf.heading(translate("report.top"))
+	rows = nil
+	for i, d := range summary.TopSpenders {
+		rows = append(rows, []string{strconv.Itoa(i + 1), d.Name, d.PoliticalParty, d.State, formatMoney(d.Total)})
+	}
+	pdf.table([]pdfColumn{
+		{"#", 10, "R"},
+		{translate("report.name"), 80, "L"},
+		{translate("report.party"), 30, "L"},
+		{translate("report.state"), 20, "L"},
+		{translate("report.total"), 40, "R"},
+	}, rows)
+
+	pdf.heading(translate("report.categories"))
+	if hasCategoryCosts(summary.Categories) {
+		pdf.chart("categories", categoryTotalsChart(summary.Categories))
+	}
+
+	rows = nil
+	for _, c := range summary.Categories {
+		rows = append(rows, []string{c.Name, strconv.Itoa(c.Deputies), formatMoney(c.Total)})
+	}
+	pdf.table([]pdfColumn{
+		{translate("report.category"), 110, "L"},
+		{translate("report.deputies"), 30, "R"},
+		{translate("report.total"), 40, "R"},
+	}, rows)
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, fmt.Errorf("error.pdf: %v", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (pdf *pdfReport) heading(text string) {
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 9, pdf.tr(text), "", 1, "L", false, 0, "")
+}
+
+// chart draws ch as a PNG the width of the page, starting a new page when it does not fit.
+func (pdf *pdfReport) chart(name string, ch renderableChart) {
+	var buffer bytes.Buffer
+	if err := ch.Render(chart.PNG, &buffer); err != nil {
+		warnf("report chart %s: %v", name, err)
+		return
+	}
+
+	options := fpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
+	info := pdf.RegisterImageOptionsReader(name, options, &buffer)
+	if info == nil {
+		return
+	}
+
+	height := pdfChartWidth * info.Height() / info.Width()
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+height > pageHeight-bottom-15 {
+		pdf.AddPage()
+	}
+
+	pdf.ImageOptions(name, 15, pdf.GetY(), pdfChartWidth, height, true, options, 0, "")
+}
+
+// table draws rows under a header that is repeated on every page the table spans.
+func (pdf *pdfReport) table(columns []pdfColumn, rows [][]string) {
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(240, 240, 240)
+		for _, c := range columns {
+			pdf.CellFormat(c.Width, pdfLineHeight+1, pdf.tr(c.Title), "B", 0, c.Align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	header()
+
+	_, pageHeight := pdf.GetPageSize()
+	for _, row := range rows {
+		if pdf.GetY()+pdfLineHeight > pageHeight-15 {
+			pdf.AddPage()
+			header()
+		}
+
+		for i, c := range columns {
+			text := pdf.tr(row[i])
+			for len(text) > 0 && pdf.GetStringWidth(text) > c.Width-2 {
+				text = text[:len(text)-1]
+			}
+			pdf.CellFormat(c.Width, pdfLineHeight, text, "", 0, c.Align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func writePDFReport() error {
+	bytes, err := encodePDFReport(newReportSummary(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("report.pdf", bytes)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+type PeriodOption struct {
+	Value string
+	Label string
+}
+
+// getPeriods reads the legislature and year dropdowns of the transparency page.
+func getPeriods(ctx context.Context) (legislatures []PeriodOption, years []PeriodOption, err error) {
+	attrValue := selector.Attribute("value")
+
+	option := func(node *html.Node) (PeriodOption, bool) {
+		value := strings.TrimSpace(attrValue.Val(node))
+		if value == "" || node.FirstChild == nil {
+			return PeriodOption{}, false
+		}
+
+		return PeriodOption{
+			Value: value,
+			Label: strings.TrimSpace(node.FirstChild.Data),
+		}, true
+	}
+
+	c := newCollector(ctx)
+
+	c.OnNode("select#legislatura option", func(req *http.Request, resp *http.Response, node *html.Node) error {
+		if o, ok := option(node); ok {
+			legislatures = append(legislatures, o)
+		}
+
+		return nil
+	})
+
+	c.OnNode("select#ano option", func(req *http.Request, resp *http.Response, node *html.Node) error {
+		if o, ok := option(node); ok {
+			years = append(years, o)
+		}
+
+		return nil
+	})
+
+	if err := c.Visit(baseURL + "/transparencia/gastos-parlamentares"); err != nil {
+		return nil, nil, err
+	}
+
+	return legislatures, years, nil
+}
+
+func printPeriods(ctx context.Context) error {
+	legislatures, years, err := getPeriods(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("legislatures:")
+	for _, o := range legislatures {
+		fmt.Printf("  %s\t%s\n", o.Value, o.Label)
+	}
+
+	fmt.Println("years:")
+	for _, o := range years {
+		fmt.Printf("  %s\t%s\n", o.Value, o.Label)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// statePopulation is the resident population per state (UF) from the IBGE 2022 census.
+var statePopulation = map[string]int64{
+	"AC": 830018,
+	"AL": 3127683,
+	"AM": 3941613,
+	"AP": 733759,
+	"BA": 14141626,
+	"CE": 8794957,
+	"DF": 2817381,
+	"ES": 3833712,
+	"GO": 7056495,
+	"MA": 6776699,
+	"MG": 20539989,
+	"MS": 2757013,
+	"MT": 3658649,
+	"PA": 8120131,
+	"PB": 3974687,
+	"PE": 9058931,
+	"PI": 3271199,
+	"PR": 11444380,
+	"RJ": 16055174,
+	"RN": 3302729,
+	"RO": 1581196,
+	"RR": 636707,
+	"RS": 10882965,
+	"SC": 7610361,
+	"SE": 2210004,
+	"SP": 44411238,
+	"TO": 1511460,
+}
+
+type StatePerCapita struct {
+	Total      float64 `json:"total"`
+	Population int64   `json:"population"`
+	PerCapita  float64 `json:"perCapita"`
+}
+
+func loadPopulationFile(name string) (map[string]int64, error) {
+	bytes, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	population := map[string]int64{}
+	if err := json.Unmarshal(bytes, &population); err != nil {
+		return nil, fmt.Errorf("error.population.file: %v", err)
+	}
+
+	return population, nil
+}
+
+func statePerCapitaMap(deputies []*Deputy, population map[string]int64) map[string]StatePerCapita {
+	stateTotalMap := map[string]float64{}
+	for _, d := range deputies {
+		stateTotalMap[d.State] += d.Total
+	}
+
+	perCapitaMap := map[string]StatePerCapita{}
+	for state, total := range stateTotalMap {
+		p, ok := population[state]
+		if !ok || p <= 0 {
+			warnf("population not found for state %s, skipping", state)
+			continue
+		}
+
+		perCapitaMap[state] = StatePerCapita{
+			Total:      total,
+			Population: p,
+			PerCapita:  total / float64(p),
+		}
+	}
+
+	return perCapitaMap
+}
+
+func writeStatePerCapita() error {
+	population := statePopulation
+	if populationFile != "" {
+		p, err := loadPopulationFile(populationFile)
+		if err != nil {
+			return err
+		}
+		population = p
+	}
+
+	bytes, err := encodeOutputJSON(statePerCapitaMap(deputiesArray, population))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("state_per_capita.json", bytes)
+}
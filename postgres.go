@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresDSN adds a postgres output, also given as -output postgres=DSN, read from
+// GODEPUTY_POSTGRES_DSN so the credentials stay off the command line.
+var postgresDSN string
+
+// postgresTypes are the PostgreSQL types of the column types.
+var postgresTypes = map[columnType]string{
+	columnString: "TEXT",
+	columnInt:    "INTEGER",
+	columnMoney:  "NUMERIC(14, 2)",
+	columnBool:   "BOOLEAN",
+	columnTime:   "TIMESTAMPTZ",
+}
+
+var postgresSchema = []string{
+	postgresCreateTable("deputies", deputyColumns,
+		"updated_at TIMESTAMPTZ NOT NULL DEFAULT now()",
+		"PRIMARY KEY (legislature, year, deputy_id)",
+	),
+	`CREATE INDEX IF NOT EXISTS deputies_party ON deputies (political_party)`,
+	postgresCreateTable("expenses", expenseColumns,
+		"FOREIGN KEY (legislature, year, deputy_id) REFERENCES deputies (legislature, year, deputy_id) ON DELETE CASCADE",
+	),
+	`CREATE INDEX IF NOT EXISTS expenses_deputy ON expenses (legislature, year, deputy_id)`,
+}
+
+var (
+	postgresUpsertDeputy  = postgresUpsert()
+	postgresInsertExpense = "INSERT INTO expenses (" + strings.Join(postgresColumnNames(expenseColumns), ", ") + ") VALUES (" + sqlPlaceholders(len(expenseColumns), true) + ")"
+)
+
+// postgresColumn names the deputy ID deputy_id, as in the expenses table.
+func postgresColumn(name string) string {
+	if name == "id" {
+		return "deputy_id"
+	}
+
+	return name
+}
+
+func postgresColumnNames(columns []tableColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, postgresColumn(c.Name))
+	}
+
+	return names
+}
+
+// postgresCreateTable creates the table name with columns, followed by the extra columns
+// and constraints.
+func postgresCreateTable(name string, columns []tableColumn, extra ...string) string {
+	var definitions []string
+	for _, c := range columns {
+		definition := postgresColumn(c.Name) + " " + postgresTypes[c.Type]
+		if !c.Optional {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+	definitions = append(definitions, extra...)
+
+	return "CREATE TABLE IF NOT EXISTS " + name + " (\n\t\t" + strings.Join(definitions, ",\n\t\t") + "\n\t)"
+}
+
+// postgresUpsert inserts a deputy, or updates every column but the key of the existing one.
+func postgresUpsert() string {
+	var updates []string
+	for _, c := range deputyColumns {
+		if !c.Key {
+			updates = append(updates, c.Name+" = EXCLUDED."+c.Name)
+		}
+	}
+	updates = append(updates, "updated_at = now()")
+
+	return "INSERT INTO deputies (" + strings.Join(postgresColumnNames(deputyColumns), ", ") + ")" +
+		" VALUES (" + sqlPlaceholders(len(deputyColumns), true) + ")" +
+		" ON CONFLICT (legislature, year, deputy_id) DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// postgresValue converts a value of a table row into what is stored in its column.
+func postgresValue(c tableColumn, v any) any {
+	if c.Optional {
+		return nullString(v.(string))
+	}
+
+	return v
+}
+
+// writePostgres upserts the deputies keyed by legislature, year and deputy ID, replacing
+// their expenses, so repeated runs keep the tables up to date. Deputies missing from a
+// run are left as they were.
+func writePostgres(ctx context.Context, dsn string, deputies []*Deputy) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range postgresSchema {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error.postgres.schema: %v", err)
+		}
+	}
+
+	upsert, err := tx.PrepareContext(ctx, postgresUpsertDeputy)
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+
+	insertExpense, err := tx.PrepareContext(ctx, postgresInsertExpense)
+	if err != nil {
+		return err
+	}
+	defer insertExpense.Close()
+
+	for _, d := range deputies {
+		_, err := upsert.ExecContext(ctx, tableValues(deputyColumns, deputyRow(d), postgresValue)...)
+		if err != nil {
+			return fmt.Errorf("error.postgres.deputy.%s: %v", d.ID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM expenses WHERE legislature = $1 AND year = $2 AND deputy_id = $3`, d.Legislature, d.Year, d.ID)
+		if err != nil {
+			return fmt.Errorf("error.postgres.expense.%s: %v", d.ID, err)
+		}
+
+		for _, e := range expenseRows(d) {
+			_, err := insertExpense.ExecContext(ctx, tableValues(expenseColumns, expenseRow(d, e), postgresValue)...)
+			if err != nil {
+				return fmt.Errorf("error.postgres.expense.%s: %v", d.ID, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// withProductivity counts the propositions each deputy authored in the year.
+var withProductivity bool
+
+// billTypes are the proposition types counted as bills; amendments are the EM* types.
+var billTypes = map[string]bool{"PL": true, "PLP": true, "PEC": true, "PDL": true, "MPV": true}
+
+type Productivity struct {
+	Propositions       int     `json:"propositions"`
+	Bills              int     `json:"bills"`
+	Amendments         int     `json:"amendments"`
+	CostPerProposition float64 `json:"costPerProposition,omitempty"`
+}
+
+type apiProposition struct {
+	ID   int    `json:"id"`
+	Type string `json:"siglaTipo"`
+}
+
+// fetchProductivity counts the propositions presented in the year with deputy among the authors.
+func fetchProductivity(ctx context.Context, deputy *Deputy) error {
+	query := url.Values{}
+	query.Set("idDeputadoAutor", deputy.ID)
+	query.Set("dataApresentacaoInicio", fmt.Sprintf("%d-01-01", year))
+	query.Set("dataApresentacaoFim", fmt.Sprintf("%d-12-31", year))
+	query.Set("itens", "100")
+
+	productivity := &Productivity{}
+	err := getAPIPages(ctx, apiURL+"/proposicoes?"+query.Encode(), func(data json.RawMessage) error {
+		var page []apiProposition
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+
+		for _, p := range page {
+			productivity.Propositions++
+			switch {
+			case billTypes[p.Type]:
+				productivity.Bills++
+			case strings.HasPrefix(p.Type, "EM"):
+				productivity.Amendments++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	deputy.Productivity = productivity
+
+	return nil
+}
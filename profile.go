@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var (
+	pprofAddr   string
+	cpuProfile  string
+	memProfile  string
+	pprofServer *http.Server
+)
+
+// startProfiling serves net/http/pprof on -pprof and starts the -cpuprofile, the
+// returned function stops them and writes -memprofile once the run is over.
+func startProfiling() (func(), error) {
+	if pprofAddr != "" {
+		pprofServer = &http.Server{Addr: pprofAddr, Handler: http.DefaultServeMux}
+		go func() {
+			infof("serving pprof on %s", pprofAddr)
+			if err := pprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errorf("pprof: %v", err)
+			}
+		}()
+	}
+
+	var cpuFile *os.File
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("error.cpu.profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error.cpu.profile: %v", err)
+		}
+		cpuFile = f
+	}
+
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+
+		if memProfile != "" {
+			if err := writeMemProfile(memProfile); err != nil {
+				errorf("%v", err)
+			}
+		}
+
+		if pprofServer != nil {
+			pprofServer.Close()
+		}
+	}, nil
+}
+
+func writeMemProfile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error.mem.profile: %v", err)
+	}
+	defer f.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("error.mem.profile: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const googleStorageScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// publishTarget is the bucket and key prefix given with -publish, e.g. s3://bucket/prefix.
+type publishTarget struct {
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+var (
+	publishURL string
+	publish    *publishTarget
+
+	// publishUploaders open the uploader of the files of a run, by the scheme of -publish.
+	// It is opened once for every file of the publish, and closed after the last one.
+	publishUploaders = map[string]func(ctx context.Context) (upload uploadFunc, close func(), err error){
+		"s3": newS3Uploader,
+		"gs": newGoogleStorageUploader,
+	}
+
+	googleStorageURL = "https://storage.googleapis.com/upload/storage/v1/b/"
+
+	newGoogleStorageClient = func(ctx context.Context) (*http.Client, error) {
+		client, _, err := googleClient(ctx, googleStorageScope)
+		return client, err
+	}
+
+	// s3Now is the time requests to S3 are signed at.
+	s3Now = time.Now
+)
+
+// uploadFunc uploads an output file to a bucket under key.
+type uploadFunc func(ctx context.Context, bucket, key string, data []byte, contentType string) error
+
+// contentTypes are the content types of the outputs that mime does not know everywhere.
+var contentTypes = map[string]string{
+	".json":    "application/json",
+	".ndjson":  "application/x-ndjson",
+	".csv":     "text/csv; charset=utf-8",
+	".md":      "text/markdown; charset=utf-8",
+	".html":    "text/html; charset=utf-8",
+	".pdf":     "application/pdf",
+	".png":     "image/png",
+	".svg":     "image/svg+xml",
+	".xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".parquet": "application/vnd.apache.parquet",
+	".avro":    "application/avro",
+	".zip":     "application/zip",
+	".gz":      "application/gzip",
+}
+
+func contentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+func parsePublish(v string) (*publishTarget, error) {
+	u, err := url.Parse(v)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid publish %q, expected s3://bucket/prefix or gs://bucket/prefix", v)
+	}
+
+	if _, ok := publishUploaders[u.Scheme]; !ok {
+		return nil, fmt.Errorf("unknown publish scheme %q, expected s3 or gs", u.Scheme)
+	}
+
+	return &publishTarget{Scheme: u.Scheme, Bucket: u.Host, Prefix: strings.Trim(u.Path, "/")}, nil
+}
+
+// publishFiles uploads every file written by the run and the -archive under the prefix
+// of the bucket once the run is over, keyed by their path under outputDir so the years
+// and periods of a run keep their directories. It stops at the first that fails.
+func publishFiles(ctx context.Context) error {
+	files := map[string]string{}
+	for _, path := range runFiles {
+		name, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = path
+	}
+	if archivePath != "" {
+		files[filepath.Base(archivePath)] = archivePath
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	upload, closeUploader, err := publishUploaders[publish.Scheme](ctx)
+	if err != nil {
+		return fmt.Errorf("error.publish.open: %s://%s: %v", publish.Scheme, publish.Bucket, err)
+	}
+	defer closeUploader()
+
+	for _, name := range names {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return err
+		}
+
+		key := path.Join(publish.Prefix, name)
+		if err := upload(ctx, publish.Bucket, key, data, contentType(name)); err != nil {
+			return fmt.Errorf("error.publish.upload: %s://%s/%s: %v", publish.Scheme, publish.Bucket, key, err)
+		}
+		debugf("published %s://%s/%s", publish.Scheme, publish.Bucket, key)
+	}
+
+	infof("published %d files to %s", len(names), publishURL)
+
+	return nil
+}
+
+// newGoogleStorageUploader authorizes one client for the publish, which uploads each file
+// to the bucket with a simple media upload.
+func newGoogleStorageUploader(ctx context.Context) (uploadFunc, func(), error) {
+	client, err := newGoogleStorageClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upload := func(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+		api := &googleAPI{client: client, url: googleStorageURL + url.PathEscape(bucket) + "/o"}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.url+"?uploadType=media&name="+url.QueryEscape(key), bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+
+		return api.do(req, nil)
+	}
+
+	return upload, client.CloseIdleConnections, nil
+}
+
+// newS3Uploader puts each file in the bucket with a request signed with AWS Signature
+// Version 4. The credentials and region come from the usual AWS_* variables, and
+// AWS_ENDPOINT_URL points it to an S3 compatible storage, addressed by path.
+func newS3Uploader(ctx context.Context) (uploadFunc, func(), error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	sessionToken, endpoint := os.Getenv("AWS_SESSION_TOKEN"), os.Getenv("AWS_ENDPOINT_URL")
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	upload := func(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+		var objectURL string
+		if endpoint != "" {
+			objectURL = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + s3EscapePath(key)
+		} else {
+			objectURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, s3EscapePath(key))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		signS3(req, data, accessKey, secretKey, sessionToken, region)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("status code %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	return upload, func() {}, nil
+}
+
+// s3EscapePath escapes every byte of the key but the unreserved ones and the slashes.
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// s3Signature holds the steps of an AWS Signature Version 4, kept apart to be checked
+// against the examples of the S3 documentation.
+type s3Signature struct {
+	CanonicalRequest string
+	StringToSign     string
+	Scope            string
+	SignedHeaders    string
+	Signature        string
+}
+
+// signS3 sets the Authorization header of req, signing its host, content hash and date.
+func signS3(req *http.Request, payload []byte, accessKey, secretKey, sessionToken, region string) {
+	now := s3Now().UTC()
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	s := signS3Request(req, payloadHash, secretKey, sessionToken, region, now)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, s.Scope, s.SignedHeaders, s.Signature))
+}
+
+// signS3Request signs the method, path, query, host, content hash and date of req at now.
+func signS3Request(req *http.Request, payloadHash, secretKey, sessionToken, region string, now time.Time) s3Signature {
+	amzDate, day := now.Format("20060102T150405Z"), now.Format("20060102")
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := []string{"host:" + host, "x-amz-content-sha256:" + payloadHash, "x-amz-date:" + amzDate}
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	if sessionToken != "" {
+		headers = append(headers, "x-amz-security-token:"+sessionToken)
+		signedHeaders += ";x-amz-security-token"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return s3Signature{
+		CanonicalRequest: canonicalRequest,
+		StringToSign:     stringToSign,
+		Scope:            scope,
+		SignedHeaders:    signedHeaders,
+		Signature:        hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	refreshOlderThan time.Duration
+
+	// previousDeputies holds the deputies of the last run by ID when -refresh-older-than is set.
+	previousDeputies map[string]*Deputy
+)
+
+// parseAge extends time.ParseDuration with a "d" suffix for days, e.g. "7d".
+func parseAge(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", v)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(v)
+}
+
+func loadDeputiesFile(name string) ([]*Deputy, error) {
+	bytes, err := readOutputFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var deputies []*Deputy
+	if err := decodeOutputJSON(bytes, &deputies); err != nil {
+		return nil, fmt.Errorf("error.deputies.file: %s: %v", name, err)
+	}
+
+	return deputies, nil
+}
+
+// loadPreviousDeputies reads the deputies.json of the last run, found whatever date
+// -name-template gave it. Without one every deputy is scraped again.
+func loadPreviousDeputies() error {
+	previousDeputies = map[string]*Deputy{}
+
+	deputies, err := loadDeputiesFile(previousOutputPath("deputies.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		warnf("no previous deputies.json in %s, scraping every deputy", outputDir)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deputies {
+		previousDeputies[d.ID] = d
+	}
+
+	return nil
+}
+
+// freshDeputy returns the previously scraped record of id when it is newer than -refresh-older-than.
+func freshDeputy(id string) (*Deputy, bool) {
+	d, ok := previousDeputies[id]
+	if !ok || d.ScrapedAt.IsZero() || time.Since(d.ScrapedAt) >= refreshOlderThan {
+		return nil, false
+	}
+
+	return d, true
+}
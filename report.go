@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	chart "github.com/wcharczuk/go-chart"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// reportTop is the number of deputies listed among the top spenders of a report.
+var reportTop = 20
+
+// reportRenderers write the reports of the report command, by the name given after it.
+var reportRenderers = map[string]func() error{
+	"html": writeHTMLReport,
+	"md":   writeMarkdownSummary,
+	"pdf":  writePDFReport,
+}
+
+// reportGroup sums the deputies of a party or a state.
+type reportGroup struct {
+	Name string
+	PartyStats
+}
+
+type reportCategory struct {
+	Name     string
+	Deputies int
+	Total    float64
+}
+
+// reportSummary is what every report shows of the deputies of a run.
+type reportSummary struct {
+	Legislature int
+	Year        int
+	GeneratedAt time.Time
+	Deputies    int
+	Total       float64
+	Parties     []reportGroup
+	States      []reportGroup
+	TopSpenders []*Deputy // every deputy, the most expensive first; each report keeps its top
+	Categories  []reportCategory
+}
+
+// runReport rebuilds the aggregations and charts from a previously written deputies.json,
+// or only writes the report named after the command, as in "godeputy report html".
+func runReport(ctx context.Context) error {
+	if reportInput == "" {
+		reportInput = previousOutputPath("deputies.json")
+	}
+
+	if len(commandArgs) == 0 {
+		return mergeDeputyFiles([]string{reportInput})
+	}
+
+	render, ok := reportRenderers[commandArgs[0]]
+	if !ok || len(commandArgs) != 1 {
+		return fmt.Errorf("usage: godeputy report [%s]", strings.Join(reportNames(), "|"))
+	}
+
+	deputies, err := loadDeputiesFile(reportInput)
+	if err != nil {
+		return err
+	}
+
+	if err := loadReportMetadata(reportInput, deputies); err != nil {
+		return err
+	}
+
+	resetResults()
+	aggregateDeputies(deputies)
+	sortDeputies()
+
+	return render()
+}
+
+func reportNames() []string {
+	var names []string
+	for name := range reportRenderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func newReportSummary(deputies []*Deputy) reportSummary {
+	summary := reportSummary{
+		GeneratedAt: time.Now().UTC(),
+		Deputies:    len(deputies),
+	}
+
+	parties, states := map[string][]*Deputy{}, map[string][]*Deputy{}
+	for _, d := range deputies {
+		summary.Total += d.Total
+		parties[d.PoliticalParty] = append(parties[d.PoliticalParty], d)
+		states[d.State] = append(states[d.State], d)
+
+		if summary.Legislature == 0 {
+			summary.Legislature, summary.Year = d.Legislature, d.Year
+		}
+	}
+
+	summary.Parties = reportGroups(parties)
+	summary.States = reportGroups(states)
+
+	summary.TopSpenders = append([]*Deputy(nil), deputies...)
+	sort.SliceStable(summary.TopSpenders, func(i, j int) bool {
+		return summary.TopSpenders[i].Total > summary.TopSpenders[j].Total
+	})
+
+	for name, spending := range categorySpendingMap(deputies) {
+		category := reportCategory{Name: name, Deputies: len(spending)}
+		for _, s := range spending {
+			category.Total += s.Value
+		}
+		summary.Categories = append(summary.Categories, category)
+	}
+	sort.Slice(summary.Categories, func(i, j int) bool {
+		if summary.Categories[i].Total != summary.Categories[j].Total {
+			return summary.Categories[i].Total > summary.Categories[j].Total
+		}
+		return summary.Categories[i].Name < summary.Categories[j].Name
+	})
+
+	return summary
+}
+
+// limit keeps the first n top spenders and categories of the summary.
+func (s reportSummary) limit(n int) reportSummary {
+	if len(s.TopSpenders) > n {
+		s.TopSpenders = s.TopSpenders[:n]
+	}
+	if len(s.Categories) > n {
+		s.Categories = s.Categories[:n]
+	}
+
+	return s
+}
+
+// reportGroups lists the stats of each group, the most expensive first.
+func reportGroups(groups map[string][]*Deputy) []reportGroup {
+	var list []reportGroup
+	for name, stats := range partyStatsMap(groups) {
+		list = append(list, reportGroup{Name: name, PartyStats: stats})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total != list[j].Total {
+			return list[i].Total > list[j].Total
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// formatMoney formats v in reais with the separators of the report language.
+func formatMoney(v float64) string {
+	tag := language.BrazilianPortuguese
+	if lang == "en" {
+		tag = language.English
+	}
+
+	return message.NewPrinter(tag).Sprintf("R$ %.2f", v)
+}
+
+// hasCategoryCosts tells whether the categories, sorted by total, have any cost to chart:
+// the bar chart needs a range above zero.
+func hasCategoryCosts(categories []reportCategory) bool {
+	return len(categories) > 0 && categories[0].Total > 0
+}
+
+// categoryTotalsChart is the bar chart of the ten categories that cost the most.
+func categoryTotalsChart(categories []reportCategory) chart.BarChart {
+	if len(categories) > 10 {
+		categories = categories[:10]
+	}
+
+	var bars []chart.Value
+	for _, c := range categories {
+		bars = append(bars, chart.Value{Label: c.Name, Value: c.Total})
+	}
+
+	return chart.BarChart{
+		Title:      translate("report.categories"),
+		TitleStyle: chart.StyleShow(),
+		Height:     512,
+		Width:      1024,
+		BarWidth:   60,
+		XAxis: chart.Style{
+			Show:                true,
+			FontSize:            8,
+			TextRotationDegrees: 45,
+		},
+		YAxis: chart.YAxis{
+			Style: chart.StyleShow(),
+			Range: &chart.ContinuousRange{
+				Min: 0,
+				Max: categories[0].Total,
+			},
+		},
+		Bars: bars,
+	}
+}
+
+// renderableChart is implemented by every go-chart chart.
+type renderableChart interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+// renderSVG renders a chart as inline SVG, or nothing when there is no data to draw.
+func renderSVG(name string, ch renderableChart) template.HTML {
+	var buffer bytes.Buffer
+	if err := ch.Render(chart.SVG, &buffer); err != nil {
+		warnf("report chart %s: %v", name, err)
+		return ""
+	}
+
+	return template.HTML(buffer.String())
+}
+
+type htmlReport struct {
+	reportSummary
+	Lang       string
+	PartyChart template.HTML
+	CostChart  template.HTML
+}
+
+// encodeHTMLReport renders the summary as a single page with its charts inlined as SVG
+// and no external resources, so it can be mailed or opened offline.
+func encodeHTMLReport(summary reportSummary) ([]byte, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"t":     translate,
+		"money": formatMoney,
+	}).Parse(reportHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := htmlReport{
+		reportSummary: summary,
+		Lang:          lang,
+	}
+	if len(report.TopSpenders) > reportTop {
+		report.TopSpenders = report.TopSpenders[:reportTop]
+	}
+	if hasPartyCosts() {
+		report.PartyChart = renderSVG("parties", politicalPartyChart())
+	}
+	if hasCategoryCosts(summary.Categories) {
+		report.CostChart = renderSVG("categories", categoryTotalsChart(summary.Categories))
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, report); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeHTMLReport() error {
+	bytes, err := encodeHTMLReport(newReportSummary(deputiesArray))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("report.html", bytes)
+}
+
+const reportHTMLTemplate = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "report.title"}} {{.Legislature}}/{{.Year}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1080px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+td.number, th.number { text-align: right; }
+th { background: #f4f4f4; cursor: pointer; user-select: none; }
+th.asc::after { content: " ▲"; }
+th.desc::after { content: " ▼"; }
+svg { max-width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<h1>{{t "report.title"}}</h1>
+<p>{{t "report.legislature"}} {{.Legislature}}, {{.Year}} · {{.Deputies}} {{t "report.deputies"}} · {{t "report.total"}} {{money .Total}}</p>
+
+<h2>{{t "report.parties"}}</h2>
+{{.PartyChart}}
+<table class="sortable">
+<thead><tr><th>{{t "report.party"}}</th><th class="number">{{t "report.deputies"}}</th><th class="number">{{t "report.total"}}</th><th class="number">{{t "report.mean"}}</th><th class="number">{{t "report.median"}}</th></tr></thead>
+<tbody>
+{{- range .Parties}}
+<tr><td>{{.Name}}</td><td class="number">{{.Count}}</td><td class="number" data-value="{{.Total}}">{{money .Total}}</td><td class="number" data-value="{{.Mean}}">{{money .Mean}}</td><td class="number" data-value="{{.Median}}">{{money .Median}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>{{t "report.states"}}</h2>
+<table class="sortable">
+<thead><tr><th>{{t "report.state"}}</th><th class="number">{{t "report.deputies"}}</th><th class="number">{{t "report.total"}}</th><th class="number">{{t "report.mean"}}</th><th class="number">{{t "report.median"}}</th></tr></thead>
+<tbody>
+{{- range .States}}
+<tr><td>{{.Name}}</td><td class="number">{{.Count}}</td><td class="number" data-value="{{.Total}}">{{money .Total}}</td><td class="number" data-value="{{.Mean}}">{{money .Mean}}</td><td class="number" data-value="{{.Median}}">{{money .Median}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>{{t "report.top"}}</h2>
+<table class="sortable">
+<thead><tr><th>{{t "report.name"}}</th><th>{{t "report.party"}}</th><th>{{t "report.state"}}</th><th class="number">{{t "report.total"}}</th></tr></thead>
+<tbody>
+{{- range .TopSpenders}}
+<tr><td>{{if .SourceURL}}<a href="{{.SourceURL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td>{{.PoliticalParty}}</td><td>{{.State}}</td><td class="number" data-value="{{.Total}}">{{money .Total}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>{{t "report.categories"}}</h2>
+{{.CostChart}}
+<table class="sortable">
+<thead><tr><th>{{t "report.category"}}</th><th class="number">{{t "report.deputies"}}</th><th class="number">{{t "report.total"}}</th></tr></thead>
+<tbody>
+{{- range .Categories}}
+<tr><td>{{.Name}}</td><td class="number">{{.Deputies}}</td><td class="number" data-value="{{.Total}}">{{money .Total}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<p><small>{{t "report.generated"}} {{.GeneratedAt.Format "2006-01-02 15:04 UTC"}}</small></p>
+
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+	th.addEventListener("click", function () {
+		var tbody = th.closest("table").tBodies[0];
+		var column = th.cellIndex;
+		var asc = !th.classList.contains("asc");
+		th.parentNode.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+		th.classList.add(asc ? "asc" : "desc");
+
+		var value = function (row) {
+			var cell = row.cells[column];
+			var v = cell.dataset.value !== undefined ? cell.dataset.value : cell.textContent.trim();
+			return isNaN(v) || v === "" ? v : Number(v);
+		};
+
+		Array.from(tbody.rows).sort(function (a, b) {
+			var x = value(a), y = value(b);
+			var order = typeof x === "number" && typeof y === "number" ? x - y : String(x).localeCompare(String(y));
+			return asc ? order : -order;
+		}).forEach(function (row) { tbody.appendChild(row); });
+	});
+});
+</script>
+</body>
+</html>
+`
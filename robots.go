@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m2tx/gocrawler/collector"
+)
+
+var (
+	// respectRobots checks the robots.txt of each host before requesting a page from it.
+	respectRobots bool
+
+	// userAgent identifies godeputy to the sites it visits and in their robots.txt.
+	userAgent = "godeputy (+https://github.com/m2tx/godeputy)"
+
+	errDisallowedByRobots = errors.New("error.robots.disallowed")
+
+	robotsMutex sync.Mutex
+	robotsHosts = map[string]*robotsRules{}
+)
+
+// robotsRules are the Allow and Disallow paths of the robots.txt group that applies to godeputy.
+type robotsRules struct {
+	allow      []string
+	disallow   []string
+	crawlDelay time.Duration
+}
+
+// parseRobots reads the group of robots.txt naming agent, or the * group when none does.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	var (
+		groups     = map[string]*robotsRules{}
+		current    []*robotsRules
+		inAgents   bool
+		scanner    = bufio.NewScanner(r)
+		groupRules = func(name string) *robotsRules {
+			if groups[name] == nil {
+				groups[name] = &robotsRules{}
+			}
+			return groups[name]
+		}
+	)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			current = append(current, groupRules(strings.ToLower(value)))
+			continue
+		}
+		inAgents = false
+
+		for _, rules := range current {
+			switch key {
+			case "allow":
+				if value != "" {
+					rules.allow = append(rules.allow, value)
+				}
+			case "disallow":
+				if value != "" {
+					rules.disallow = append(rules.disallow, value)
+				}
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	for name, rules := range groups {
+		if name != "*" && strings.Contains(agent, name) {
+			return rules
+		}
+	}
+	if rules, ok := groups["*"]; ok {
+		return rules
+	}
+
+	return &robotsRules{}
+}
+
+// allowed applies the longest matching path, with Allow winning a tie.
+func (r *robotsRules) allowed(path string) bool {
+	longest, allowed := -1, true
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > longest {
+			longest, allowed = len(p), false
+		}
+	}
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) >= longest {
+			longest, allowed = len(p), true
+		}
+	}
+
+	return allowed
+}
+
+// checkRobots fails with errDisallowedByRobots when the robots.txt of the host of u
+// disallows its path. Each host's robots.txt is fetched once, a missing one allows
+// everything, and its Crawl-delay raises -rate-limit when it is longer.
+func checkRobots(ctx context.Context, client collector.HTTPClient, u *url.URL) error {
+	if u.Path == "/robots.txt" {
+		return nil
+	}
+
+	robotsMutex.Lock()
+	defer robotsMutex.Unlock()
+
+	rules, ok := robotsHosts[u.Host]
+	if !ok {
+		var err error
+		rules, err = fetchRobots(ctx, client, u)
+		if err != nil {
+			return err
+		}
+		robotsHosts[u.Host] = rules
+
+		if rules.crawlDelay > 0 && raiseRequestInterval(rules.crawlDelay) {
+			infof("robots.txt of %s asks for a crawl delay of %s", u.Host, rules.crawlDelay)
+		}
+	}
+
+	if !rules.allowed(u.EscapedPath()) {
+		return fmt.Errorf("%w: %s", errDisallowedByRobots, u)
+	}
+
+	return nil
+}
+
+func fetchRobots(ctx context.Context, client collector.HTTPClient, u *url.URL) (*robotsRules, error) {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	debugf("visit %s", robotsURL.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error.robots: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}, nil
+	}
+
+	return parseRobots(resp.Body, userAgent), nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// yearMonth is a month of a given year of a rolling -period.
+type yearMonth struct {
+	Year  int
+	Month int
+}
+
+func (m yearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d", m.Year, m.Month)
+}
+
+var (
+	// period holds the months of -period, which may cross the turn of the year.
+	period []yearMonth
+
+	// periodLabel is -period as given, recorded on the deputies of the rolling dataset.
+	periodLabel string
+)
+
+// parsePeriod takes a range of up to twelve months, e.g. 2023-07:2024-06, so that the
+// monthly figures of a deputy can still be keyed by month.
+func parsePeriod(v string) ([]yearMonth, error) {
+	first, last, ok := strings.Cut(v, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid period %q, expected YYYY-MM:YYYY-MM", v)
+	}
+
+	from, err := parseYearMonth(first)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parseYearMonth(last)
+	if err != nil {
+		return nil, err
+	}
+
+	var months []yearMonth
+	for m := from; m.Year < to.Year || m.Year == to.Year && m.Month <= to.Month; {
+		months = append(months, m)
+
+		m.Month++
+		if m.Month > 12 {
+			m.Year, m.Month = m.Year+1, 1
+		}
+	}
+
+	if len(months) == 0 || len(months) > 12 {
+		return nil, fmt.Errorf("invalid period %q, expected from 1 to 12 months", v)
+	}
+
+	return months, nil
+}
+
+func parseYearMonth(v string) (yearMonth, error) {
+	y, m, ok := strings.Cut(strings.TrimSpace(v), "-")
+
+	year, errYear := strconv.Atoi(y)
+	month, errMonth := strconv.Atoi(m)
+	if !ok || errYear != nil || errMonth != nil || month < 1 || month > 12 {
+		return yearMonth{}, fmt.Errorf("invalid month %q, expected YYYY-MM", v)
+	}
+
+	return yearMonth{Year: year, Month: month}, nil
+}
+
+// periodMonths groups the months of -period by year, in order.
+func periodMonths(months []yearMonth) (years []int, byYear map[int][]int) {
+	byYear = map[int][]int{}
+	for _, m := range months {
+		if _, ok := byYear[m.Year]; !ok {
+			years = append(years, m.Year)
+		}
+		byYear[m.Year] = append(byYear[m.Year], m.Month)
+	}
+
+	return years, byYear
+}
+
+// scrapePeriod scrapes the months of -period of each year it spans into its own
+// directory under outputDir, and writes a single rolling dataset, with the figures of
+// each deputy added over the whole period, into outputDir itself.
+func scrapePeriod(ctx context.Context) error {
+	baseDir, baseYear, baseLegislature, baseMonths := outputDir, year, legislatury, months
+	defer func() {
+		outputDir, year, legislatury, months = baseDir, baseYear, baseLegislature, baseMonths
+	}()
+
+	periodYears, byYear := periodMonths(period)
+
+	var (
+		perYear [][]*Deputy
+		failed  int
+	)
+	for _, y := range periodYears {
+		year, legislatury, months = y, legislatureOfYear(y), byYear[y]
+		outputDir = filepath.Join(baseDir, strconv.Itoa(y))
+
+		infof("scraping legislature %d year %d months %v", legislatury, year, months)
+		var partial *partialFailureError
+		err := scrape(ctx)
+		if errors.As(err, &partial) {
+			failed += partial.Failed
+		} else if err != nil {
+			return err
+		}
+
+		perYear = append(perYear, deputiesArray)
+	}
+
+	outputDir = baseDir
+
+	resetResults()
+	aggregateDeputies(mergePeriodDeputies(perYear))
+	if sortByID {
+		sortDeputies()
+	}
+
+	if err := writePoliticalPartyMap(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return &partialFailureError{Failed: failed}
+	}
+
+	return nil
+}
+
+// mergePeriodDeputies adds up the records of each deputy over the years of the period.
+// The latest record is kept as the base, so the party and enrichments are the current ones.
+// As the base keeps the latest year, MonthYears tells the year of each of its months.
+func mergePeriodDeputies(perYear [][]*Deputy) []*Deputy {
+	var (
+		merged []*Deputy
+		byID   = map[string]*Deputy{}
+	)
+	for i := len(perYear) - 1; i >= 0; i-- {
+		for _, d := range perYear[i] {
+			base, ok := byID[d.ID]
+			if !ok {
+				copied := *d
+				copied.Period = periodLabel
+				copied.MonthlyTotals, copied.MonthlyQuotaDetails, copied.MonthYears = nil, nil, nil
+				addMonths(&copied, d)
+				byID[d.ID] = &copied
+				merged = append(merged, &copied)
+				continue
+			}
+
+			addDeputyFigures(base, d)
+			base.Total += d.Total
+			base.SourceURL = strings.TrimSpace(d.SourceURL + " " + base.SourceURL)
+
+			addMonths(base, d)
+		}
+	}
+
+	return merged
+}
+
+// addMonths copies the monthly figures of d to base, recording the year they belong to.
+func addMonths(base, d *Deputy) {
+	for month, total := range d.MonthlyTotals {
+		if base.MonthlyTotals == nil {
+			base.MonthlyTotals = map[int]float64{}
+		}
+		base.MonthlyTotals[month] = total
+		setMonthYear(base, month, d.Year)
+	}
+	for month, details := range d.MonthlyQuotaDetails {
+		if base.MonthlyQuotaDetails == nil {
+			base.MonthlyQuotaDetails = map[int][]CostDetail{}
+		}
+		base.MonthlyQuotaDetails[month] = details
+		setMonthYear(base, month, d.Year)
+	}
+}
+
+// monthYear is the year a month of the monthly figures of d belongs to.
+func (d *Deputy) monthYear(month int) int {
+	if year, ok := d.MonthYears[month]; ok {
+		return year
+	}
+
+	return d.Year
+}
+
+func setMonthYear(d *Deputy, month, year int) {
+	if d.MonthYears == nil {
+		d.MonthYears = map[int]int{}
+	}
+	d.MonthYears[month] = year
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed deputies.schema.json
+var deputiesSchemaJSON []byte
+
+var validateOutput bool
+
+// compileDeputiesSchema compiles the embedded schema, or only its definition at fragment,
+// e.g. "#/$defs/deputy".
+func compileDeputiesSchema(fragment string) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+
+	if err := compiler.AddResource("deputies.schema.json", bytes.NewReader(deputiesSchemaJSON)); err != nil {
+		return nil, err
+	}
+
+	return compiler.Compile("deputies.schema.json" + fragment)
+}
+
+// validateDeputiesJSON checks an encoded deputies.json against the embedded schema.
+func validateDeputiesJSON(data []byte) error {
+	schema, err := compileDeputiesSchema("")
+	if err != nil {
+		return fmt.Errorf("error.schema.compile: %v", err)
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("error.schema.unmarshal: %v", err)
+	}
+
+	if err := schema.Validate(v); err != nil {
+		return fmt.Errorf("error.schema.validate: %v", err)
+	}
+
+	return nil
+}
+
+// validateDeputiesNDJSON checks every line of a deputies.ndjson against the deputy definition.
+func validateDeputiesNDJSON(data []byte) error {
+	schema, err := compileDeputiesSchema("#/$defs/deputy")
+	if err != nil {
+		return fmt.Errorf("error.schema.compile: %v", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var v any
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return fmt.Errorf("error.schema.unmarshal: line %d: %v", line, err)
+		}
+
+		if err := schema.Validate(v); err != nil {
+			return fmt.Errorf("error.schema.validate: line %d: %v", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// validateDeputiesFile checks a deputies.json or deputies.ndjson, compressed or not.
+func validateDeputiesFile(name string) error {
+	data, err := readOutputFile(name)
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(strings.TrimSuffix(name, ".gz")) == ".ndjson" {
+		return validateDeputiesNDJSON(data)
+	}
+
+	return validateDeputiesJSON(data)
+}
+
+// runValidate checks the files given as arguments, by default the deputies.json of -out,
+// against the embedded schema, reporting every file that does not match.
+func runValidate(ctx context.Context) error {
+	names := commandArgs
+	if len(names) == 0 {
+		names = []string{previousOutputPath("deputies.json")}
+	}
+
+	var failed int
+	for _, name := range names {
+		if err := validateDeputiesFile(name); err != nil {
+			errorf("%s: %v", name, err)
+			failed++
+			continue
+		}
+
+		infof("%s matches the schema", name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("error.schema.validate: %d of %d files do not match the schema", failed, len(names))
+	}
+
+	return nil
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	// senadoCEAPSURL is the yearly senators' quota (CEAPS) dump published by the Senado.
+	senadoCEAPSURL = "https://www.senado.gov.br/transparencia/LAI/verba/despesa_ceaps_%d.csv"
+
+	// senadoFile reads the CEAPS dump from disk instead of downloading it.
+	senadoFile string
+
+	senadoAPIURL = "https://legis.senado.leg.br/dadosabertos"
+)
+
+// senatorSubsidies are the monthly gross salaries (subsídio) of the members of the
+// Congress, set by Decretos Legislativos 805/2010, 210/2014 and 172/2022, by the month
+// they apply from. The Senado does not publish them with the CEAPS dump.
+var senatorSubsidies = []struct {
+	From   yearMonth
+	Salary float64
+}{
+	{yearMonth{2011, 2}, 26723.13},
+	{yearMonth{2015, 2}, 33763.00},
+	{yearMonth{2023, 4}, 39293.32},
+	{yearMonth{2024, 2}, 41650.92},
+	{yearMonth{2025, 2}, 44008.52},
+}
+
+type senator struct {
+	Identification struct {
+		Code           string `json:"CodigoParlamentar"`
+		Name           string `json:"NomeParlamentar"`
+		PoliticalParty string `json:"SiglaPartidoParlamentar"`
+		State          string `json:"UfParlamentar"`
+	} `json:"IdentificacaoParlamentar"`
+	Mandates struct {
+		Mandate senadoList[senatorMandate] `json:"Mandato"`
+	} `json:"Mandatos"`
+}
+
+// senatorMandate lists the periods a senator was in exercise of a mandate, which ended
+// with a leave or the mandate itself; the current one has no end.
+type senatorMandate struct {
+	Exercises struct {
+		Exercise senadoList[struct {
+			Start string `json:"DataInicio"`
+			End   string `json:"DataFim"`
+		}] `json:"Exercicio"`
+	} `json:"Exercicios"`
+}
+
+// senadoList decodes the lists of the Senado API, which are a single object instead of
+// an array when they have one item.
+type senadoList[T any] []T
+
+func (l *senadoList[T]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte("[")) {
+		return json.Unmarshal(data, (*[]T)(l))
+	}
+
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*l = senadoList[T]{item}
+
+	return nil
+}
+
+// senadoSource reads the senators' CEAPS expenses and adds their subsídio, so the
+// members come out of ListMembers complete.
+type senadoSource struct{}
+
+func init() {
+	RegisterSource("senado", func() Source { return senadoSource{} })
+}
+
+// runSenado builds the same outputs as a scrape for the senators' CEAPS expenses, in
+// the senado directory of -out.
+func runSenado(ctx context.Context) error {
+	return runSource(ctx, senadoSource{}, "senado")
+}
+
+func (senadoSource) ListMembers(ctx context.Context) ([]*Deputy, error) {
+	senators, err := listSenators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := senadoFile
+	if name == "" {
+		name = fmt.Sprintf(senadoCEAPSURL, year)
+	}
+
+	data, err := readDump(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCEAPSCSV(data, name, senators)
+}
+
+func (senadoSource) FetchDetails(ctx context.Context, member *Deputy) error {
+	return nil
+}
+
+// listSenators returns the senators of the legislature by upper case name, which is
+// how the CEAPS dump identifies them.
+func listSenators(ctx context.Context) (map[string]*Deputy, error) {
+	url := fmt.Sprintf("%s/senador/lista/legislatura/%d.json", senadoAPIURL, legislatury)
+	debugf("visit %s", url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := (&contextClient{ctx: ctx, client: httpClient}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error.senado.status: %s %s", url, resp.Status)
+	}
+
+	var body struct {
+		List struct {
+			Senators struct {
+				Senator []senator `json:"Parlamentar"`
+			} `json:"Parlamentares"`
+		} `json:"ListaParlamentarLegislatura"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error.senado.decode: %v", err)
+	}
+
+	senators := map[string]*Deputy{}
+	now := time.Now().UTC()
+	for _, s := range body.List.Senators.Senator {
+		senators[strings.ToUpper(s.Identification.Name)] = newSenator(s, now)
+	}
+
+	return senators, nil
+}
+
+// newSenator returns the member of a senator, with the periods of -ano it was in exercise.
+func newSenator(s senator, now time.Time) *Deputy {
+	id := s.Identification
+	deputy := &Deputy{
+		ID:             id.Code,
+		Name:           id.Name,
+		PoliticalParty: id.PoliticalParty,
+		State:          id.State,
+	}
+
+	yearStart, yearEnd := mandateYear(now)
+	for _, m := range s.Mandates.Mandate {
+		for _, e := range m.Exercises.Exercise {
+			start, err := time.Parse("2006-01-02", e.Start)
+			if err != nil {
+				continue
+			}
+
+			end := yearEnd
+			if e.End != "" {
+				if end, err = time.Parse("2006-01-02", e.End); err != nil {
+					continue
+				}
+			}
+
+			addExercisePeriod(deputy, start, end, yearStart, yearEnd)
+		}
+	}
+	sort.Slice(deputy.ExercisePeriods, func(i, j int) bool {
+		return deputy.ExercisePeriods[i].Start < deputy.ExercisePeriods[j].Start
+	})
+
+	return deputy
+}
+
+// parseCEAPSCSV groups the rows of the CEAPS dump by senator. The dump is Latin-1 encoded,
+// starts with a line telling when it was updated and writes amounts with a decimal comma.
+// Senators missing from senators keep their name as ID and have no party or state.
+func parseCEAPSCSV(data []byte, sourceURL string, senators map[string]*Deputy) ([]*Deputy, error) {
+	var r io.Reader = bytes.NewReader(data)
+	if !utf8.Valid(data) {
+		r = charmap.ISO8859_1.NewDecoder().Reader(r)
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	var index map[string]int
+	for index == nil {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("error.ceaps.header: %v", err)
+		}
+
+		if !containsFold(record, "SENADOR") {
+			continue
+		}
+
+		index = map[string]int{}
+		for i, column := range record {
+			index[strings.TrimSpace(column)] = i
+		}
+	}
+
+	for _, column := range []string{"ANO", "MES", "SENADOR", "TIPO_DESPESA", "VALOR_REEMBOLSADO"} {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("error.ceaps.header: missing column %q", column)
+		}
+	}
+
+	var (
+		deputies  []*Deputy
+		byName    = map[string]*Deputy{}
+		scrapedAt = time.Now().UTC()
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error.ceaps.line: %v", err)
+		}
+
+		field := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		name := strings.ToUpper(field("SENADOR"))
+		if name == "" {
+			continue
+		}
+		if y, err := strconv.Atoi(field("ANO")); err == nil && y != year {
+			continue
+		}
+		if len(months) > 0 {
+			if m, err := strconv.Atoi(field("MES")); err != nil || !containsMonth(months, m) {
+				continue
+			}
+		}
+
+		value, err := parseCEAPValue(field("VALOR_REEMBOLSADO"))
+		if err != nil {
+			warnf("skipping expense of %s in %s: %v", field("SENADOR"), sourceURL, err)
+			continue
+		}
+
+		deputy, ok := byName[name]
+		if !ok {
+			deputy = &Deputy{ID: name, Name: field("SENADOR")}
+			if s, ok := senators[name]; ok {
+				*deputy = *s
+			}
+			deputy.Legislature, deputy.Year = legislatury, year
+			deputy.SourceURL = sourceURL
+			deputy.ScrapedAt = scrapedAt
+			deputy.MissingFields = nil
+			for _, f := range portalOnlyFields {
+				if f != "salary" {
+					deputy.MissingFields = append(deputy.MissingFields, f)
+				}
+			}
+
+			byName[name] = deputy
+			deputies = append(deputies, deputy)
+		}
+
+		addDeputyFigures(deputy, quotaExpense(field("TIPO_DESPESA"), value, CostDetail{
+			Date:           field("DATA"),
+			SupplierName:   field("FORNECEDOR"),
+			SupplierCNPJ:   field("CNPJ_CPF"),
+			DocumentNumber: field("DOCUMENTO"),
+		}))
+	}
+
+	now := time.Now()
+	for _, d := range deputies {
+		d.Salary = senatorSalary(year, months, d.ExercisePeriods, now)
+		d.Total = d.Salary + d.ParliamentaryQuota
+		prorate(d)
+	}
+
+	return deputies, nil
+}
+
+// senatorSalary sums the subsídio of the months of y, or of the given months, that have
+// started by now, each prorated by the days of it in the periods in exercise. Without
+// periods, as for the senators missing from the list, the whole months are counted.
+func senatorSalary(y int, months []int, periods []ExercisePeriod, now time.Time) float64 {
+	var salary float64
+	for m := 1; m <= 12; m++ {
+		if len(months) > 0 && !containsMonth(months, m) {
+			continue
+		}
+		if y > now.Year() || y == now.Year() && m > int(now.Month()) {
+			break
+		}
+
+		for i := len(senatorSubsidies) - 1; i >= 0; i-- {
+			s := senatorSubsidies[i]
+			if s.From.Year < y || s.From.Year == y && s.From.Month <= m {
+				salary += s.Salary * monthInExercise(y, m, periods)
+				break
+			}
+		}
+	}
+
+	return salary
+}
+
+// monthInExercise is the share of the days of the month m of y within periods, or 1
+// without periods.
+func monthInExercise(y, m int, periods []ExercisePeriod) float64 {
+	if len(periods) == 0 {
+		return 1
+	}
+
+	first := time.Date(y, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+
+	var days int
+	for _, p := range periods {
+		start, err := time.Parse("2006-01-02", p.Start)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("2006-01-02", p.End)
+		if err != nil {
+			continue
+		}
+
+		if start.Before(first) {
+			start = first
+		}
+		if end.After(last) {
+			end = last
+		}
+		if !end.Before(start) {
+			days += int(end.Sub(start).Hours()/24) + 1
+		}
+	}
+
+	return float64(days) / float64(last.Day())
+}
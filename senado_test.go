@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseCEAPSCSV(t *testing.T) {
+	dump, err := charmap.ISO8859_1.NewEncoder().String(`"ULTIMA ATUALIZACAO";"01/02/2025 02:00"
+"ANO";"MES";"SENADOR";"TIPO_DESPESA";"VALOR_REEMBOLSADO"
+"2024";"1";"FLÁVIO ARNS";"Passagens aéreas";"1.200,50"
+"2024";"2";"FLÁVIO ARNS";"Passagens aéreas";"99,50"
+"2024";"2";"FULANO";"Aluguel de imóveis";"3000"
+`)
+	require.NoError(t, err)
+
+	senators := map[string]*Deputy{
+		"FLÁVIO ARNS": {ID: "345", Name: "Flávio Arns", PoliticalParty: "PSB", State: "PR", ExercisePeriods: []ExercisePeriod{{Start: "2024-01-01", End: "2024-06-30"}}, DaysInOffice: 182},
+	}
+
+	deputies, err := parseCEAPSCSV([]byte(dump), "despesa_ceaps_2024.csv", senators)
+	require.NoError(t, err)
+	require.Len(t, deputies, 2)
+
+	assert.Equal(t, "345", deputies[0].ID)
+	assert.Equal(t, "PSB", deputies[0].PoliticalParty)
+	assert.Equal(t, 1300.0, deputies[0].ParliamentaryQuota)
+	assert.InDelta(t, 39293.32+5*41650.92, deputies[0].Salary, 0.001)
+	assert.InDelta(t, deputies[0].Salary+1300, deputies[0].Total, 0.001)
+	assert.InDelta(t, deputies[0].Total/182, deputies[0].CostPerDayInOffice, 0.001)
+	assert.NotContains(t, deputies[0].MissingFields, "salary")
+	assert.Equal(t, []CostDetail{{Description: "Passagens aéreas", Value: 1300}}, deputies[0].ParliamentaryQuotaDetails)
+
+	assert.Equal(t, "FULANO", deputies[1].ID)
+	assert.InDelta(t, 39293.32+11*41650.92, deputies[1].Salary, 0.001)
+	assert.InDelta(t, deputies[1].Salary+3000, deputies[1].Total, 0.001)
+}
+
+func TestSenatorSalary(t *testing.T) {
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.InDelta(t, 12*33763.00, senatorSalary(2022, nil, nil, now), 0.001)
+	assert.InDelta(t, 3*33763.00+9*39293.32, senatorSalary(2023, nil, nil, now), 0.001)
+	assert.InDelta(t, 39293.32+2*41650.92, senatorSalary(2024, nil, nil, now), 0.001)
+	assert.InDelta(t, 41650.92, senatorSalary(2024, []int{2, 6}, nil, now), 0.001)
+	assert.Zero(t, senatorSalary(2025, nil, nil, now))
+
+	// in exercise from February 15 of 2023, the 14 days of February out of 28
+	periods := []ExercisePeriod{{Start: "2023-02-15", End: "2023-12-31"}}
+	assert.InDelta(t, 33763.00/2+33763.00+9*39293.32, senatorSalary(2023, nil, periods, now), 0.001)
+}
+
+func TestNewSenator(t *testing.T) {
+	defer func(y int) { year = y }(year)
+	year = 2023
+
+	var s senator
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"IdentificacaoParlamentar": {"CodigoParlamentar": "345", "NomeParlamentar": "Flávio Arns", "SiglaPartidoParlamentar": "PSB", "UfParlamentar": "PR"},
+		"Mandatos": {"Mandato": {"Exercicios": {"Exercicio": [
+			{"DataInicio": "2023-06-01"},
+			{"DataInicio": "2019-02-01", "DataFim": "2023-03-31"}
+		]}}}
+	}`), &s))
+
+	senator := newSenator(s, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "345", senator.ID)
+	assert.Equal(t, []ExercisePeriod{{Start: "2023-01-01", End: "2023-03-31"}, {Start: "2023-06-01", End: "2023-12-31"}}, senator.ExercisePeriods)
+	assert.Equal(t, 90+214, senator.DaysInOffice)
+	assert.InDelta(t, 3*33763.00+9*39293.32-2*39293.32, senatorSalary(2023, nil, senator.ExercisePeriods, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)), 0.001)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+var (
+	reportInput string
+	serveAddr   = ":8080"
+)
+
+// runServe serves the output directory and a filterable view of deputies.json,
+// so previously scraped data can be browsed without crawling the site again.
+func runServe(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(outputDir)))
+	mux.HandleFunc("/api/deputies", serveDeputies)
+
+	server := &http.Server{
+		Addr:    serveAddr,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	infof("serving %s on %s", outputDir, serveAddr)
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// serveDeputies answers /api/deputies, optionally filtered by the uf, partido and deputado query parameters.
+func serveDeputies(w http.ResponseWriter, r *http.Request) {
+	deputies, err := loadDeputiesFile(previousOutputPath("deputies.json"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	ufs := splitList(query.Get("uf"))
+	parties := splitList(query.Get("partido"))
+	ids := splitList(query.Get("deputado"))
+
+	filtered := make([]*Deputy, 0, len(deputies))
+	for _, d := range deputies {
+		if len(ufs) > 0 && !containsFold(ufs, d.State) {
+			continue
+		}
+		if len(parties) > 0 && !containsFold(parties, d.PoliticalParty) {
+			continue
+		}
+		if len(ids) > 0 && !containsFold(ids, d.ID) {
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filtered)
+}
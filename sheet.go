@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	sheetURL    string
+	sheetFormat = "json"
+
+	sheetClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+func encodeSheetRows(deputies []*Deputy) (body []byte, contentType string, err error) {
+	if sheetFormat == "csv" {
+		body, err = encodeCSV(deputyCSVRecords(deputies))
+
+		return body, "text/csv", err
+	}
+
+	body, err = json.Marshal(deputies)
+
+	return body, "application/json", err
+}
+
+func postSheetRows(ctx context.Context, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sheetURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := sheetClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("status code %d for %s", resp.StatusCode, sheetURL)
+	}
+
+	return nil
+}
+
+func writeSheet(ctx context.Context, deputies []*Deputy) error {
+	body, contentType, err := encodeSheetRows(deputies)
+	if err != nil {
+		return fmt.Errorf("error.sheet.encode: %v", err)
+	}
+
+	if err := postSheetRows(ctx, body, contentType); err != nil {
+		return fmt.Errorf("error.sheet.post: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deputySink receives every batch flushed by the deputy queue.
+type deputySink struct {
+	Name  string
+	Write func(ctx context.Context, deputies []*Deputy) error
+}
+
+var (
+	deputySinks []deputySink
+
+	sinkRetries = 3
+	sinkBackoff = time.Second
+)
+
+type deadLetter struct {
+	Sink     string    `json:"sink"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+	Deputies []*Deputy `json:"deputies"`
+}
+
+// writeSinks hands the batch to every sink, retrying each one with an exponential
+// backoff and dead-lettering the batch when a sink keeps failing.
+func writeSinks(ctx context.Context, deputies []*Deputy) {
+	for _, sink := range deputySinks {
+		err := writeSinkWithRetry(ctx, sink, deputies)
+		if err == nil {
+			continue
+		}
+
+		errorf("sink %s failed for %d deputies: %v", sink.Name, len(deputies), err)
+
+		if err := writeDeadLetter(sink, deputies, err); err != nil {
+			errorf("%v", err)
+		}
+	}
+}
+
+func writeSinkWithRetry(ctx context.Context, sink deputySink, deputies []*Deputy) error {
+	backoff := sinkBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := sink.Write(ctx, deputies)
+		if err == nil || attempt == sinkRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func writeDeadLetter(sink deputySink, deputies []*Deputy, sinkErr error) error {
+	bytes, err := json.Marshal(deadLetter{
+		Sink:     sink.Name,
+		Error:    sinkErr.Error(),
+		Time:     time.Now().UTC(),
+		Deputies: deputies,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(outputDir, templateName("dead_letter.ndjson")), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(bytes, '\n'))
+
+	return err
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// sources lists where the deputies and their costs come from, in fallback order:
+// html scrapes the transparency portal, api reads the Dados Abertos REST API.
+var sources = []string{"html"}
+
+// portalOnlyFields are the figures only the transparency portal publishes, marked
+// missing on deputies built from the APIs and open data dumps.
+var portalOnlyFields = []string{"salary", "officeBudget", "travelExpenses", "housingAllowance"}
+
+// sourceFields maps the extractor names accepted by -fields to the figures they fill.
+var sourceFields = []struct {
+	Extractor string
+	Field     string
+}{
+	{"salary", "salary"},
+	{"office", "officeBudget"},
+	{"quota", "parliamentaryQuota"},
+	{"details", "parliamentaryQuotaDetails"},
+	{"tickets", "airTickets"},
+	{"travel", "travelExpenses"},
+	{"housing", "housingAllowance"},
+}
+
+// parseSources accepts a house registered with RegisterSource, such as senado or alesp,
+// and for the Câmara the html and api sources in fallback order, e.g. camara,api,html.
+// The house is kept in house and the Câmara sources in sources.
+func parseSources(v string) error {
+	var (
+		selected string
+		parsed   []string
+	)
+	for _, name := range splitList(v) {
+		if _, ok := sourceFactories[name]; ok {
+			if selected != "" {
+				return fmt.Errorf("source selects a single house, got %q and %q", selected, name)
+			}
+			selected = name
+			continue
+		}
+
+		if name != "html" && name != "api" {
+			return fmt.Errorf("unknown source %q, expected html, api or one of %s", name, strings.Join(registeredSources(), ", "))
+		}
+		if containsFold(parsed, name) {
+			return fmt.Errorf("source %q given twice", name)
+		}
+		parsed = append(parsed, name)
+	}
+
+	if selected == "" && len(parsed) == 0 {
+		return fmt.Errorf("at least one source is required")
+	}
+	if selected == "" {
+		selected = "camara"
+	}
+	if selected != "camara" && len(parsed) > 0 {
+		return fmt.Errorf("html and api are sources of the Câmara, not of %s", selected)
+	}
+	if len(parsed) == 0 {
+		parsed = []string{"html"}
+	}
+
+	house, sources = selected, parsed
+
+	return nil
+}
+
+func addFieldSource(deputy *Deputy, field, name string) {
+	if deputy.FieldSources == nil {
+		deputy.FieldSources = map[string]string{}
+	}
+
+	current := deputy.FieldSources[field]
+	if current == "" {
+		deputy.FieldSources[field] = name
+		return
+	}
+
+	if !containsFold(strings.Split(current, ","), name) {
+		deputy.FieldSources[field] = current + "," + name
+	}
+}
+
+// copyField copies a single figure of src into dst.
+func copyField(dst, src *Deputy, field string) {
+	switch field {
+	case "salary":
+		dst.Salary = src.Salary
+	case "officeBudget":
+		dst.OfficeBudget = src.OfficeBudget
+	case "parliamentaryQuota":
+		dst.ParliamentaryQuota = src.ParliamentaryQuota
+	case "parliamentaryQuotaDetails":
+		dst.ParliamentaryQuotaDetails = src.ParliamentaryQuotaDetails
+	case "airTickets":
+		dst.AirTickets = src.AirTickets
+	case "travelExpenses":
+		dst.TravelExpenses = src.TravelExpenses
+		dst.TravelExpensesDetails = src.TravelExpensesDetails
+	case "housingAllowance":
+		dst.HousingAllowance = src.HousingAllowance
+		dst.FunctionalApartment = src.FunctionalApartment
+	}
+}
+
+// visitHybridDeputyDetails tries each source in order, taking from each one the figures
+// the previous ones failed to provide, and records in FieldSources where each came from.
+func visitHybridDeputyDetails(ctx context.Context, deputy *Deputy, month int) error {
+	deputy.Salary, deputy.OfficeBudget, deputy.ParliamentaryQuota, deputy.TravelExpenses = 0, 0, 0, 0
+	deputy.HousingAllowance, deputy.FunctionalApartment, deputy.AirTickets = 0, false, 0
+	deputy.ParliamentaryQuotaDetails, deputy.TravelExpensesDetails = nil, nil
+	deputy.MissingFields, deputy.FieldSources = nil, nil
+
+	active := map[string]bool{}
+	for _, name := range collectedFields() {
+		active[name] = true
+	}
+
+	var pending []string
+	for _, f := range sourceFields {
+		if active[f.Extractor] {
+			pending = append(pending, f.Field)
+		}
+	}
+
+	var (
+		urls    []string
+		lastErr error
+	)
+	for _, name := range sources {
+		if len(pending) == 0 {
+			break
+		}
+
+		fetched := &Deputy{ID: deputy.ID}
+		if err := visitSource(ctx, name, fetched, month); err != nil {
+			warnf("source %s failed for deputy %s: %v", name, deputy.ID, err)
+			lastErr = err
+			continue
+		}
+
+		var missing []string
+		for _, field := range pending {
+			if containsFold(fetched.MissingFields, field) {
+				missing = append(missing, field)
+				continue
+			}
+			copyField(deputy, fetched, field)
+			addFieldSource(deputy, field, name)
+		}
+
+		if len(missing) < len(pending) {
+			urls = append(urls, fetched.SourceURL)
+		}
+		pending = missing
+	}
+
+	if len(urls) == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	for _, field := range pending {
+		markMissing(deputy, field)
+	}
+	deputy.SourceURL = strings.Join(urls, " ")
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTypes are the SQLite types of the column types.
+var sqliteTypes = map[columnType]string{
+	columnString: "TEXT",
+	columnInt:    "INTEGER",
+	columnMoney:  "REAL",
+	columnBool:   "INTEGER",
+	columnTime:   "TEXT",
+}
+
+var sqliteSchema = []string{
+	sqliteCreateTable("deputies", deputyColumns, "PRIMARY KEY (legislature, year, id)"),
+	`CREATE INDEX IF NOT EXISTS deputies_party ON deputies (political_party)`,
+	`CREATE INDEX IF NOT EXISTS deputies_state ON deputies (state)`,
+	sqliteCreateTable("expenses", expenseColumns, ""),
+	`CREATE INDEX IF NOT EXISTS expenses_deputy ON expenses (legislature, year, deputy_id)`,
+	`CREATE INDEX IF NOT EXISTS expenses_category ON expenses (category)`,
+	`CREATE TABLE IF NOT EXISTS parties (
+		legislature INTEGER NOT NULL,
+		year INTEGER NOT NULL,
+		party TEXT NOT NULL,
+		deputies INTEGER NOT NULL,
+		total REAL NOT NULL,
+		PRIMARY KEY (legislature, year, party)
+	)`,
+}
+
+// sqliteCreateTable creates the table name with columns, followed by the constraint when given.
+func sqliteCreateTable(name string, columns []tableColumn, constraint string) string {
+	var definitions []string
+	for _, c := range columns {
+		definition := c.Name + " " + sqliteTypes[c.Type]
+		if !c.Optional {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+	if constraint != "" {
+		definitions = append(definitions, constraint)
+	}
+
+	return "CREATE TABLE IF NOT EXISTS " + name + " (\n\t\t" + strings.Join(definitions, ",\n\t\t") + "\n\t)"
+}
+
+// sqliteValue converts a value of a table row into what is stored in its column.
+func sqliteValue(c tableColumn, v any) any {
+	switch {
+	case c.Type == columnTime:
+		return v.(time.Time).Format("2006-01-02T15:04:05Z07:00")
+	case c.Optional:
+		return nullString(v.(string))
+	}
+
+	return v
+}
+
+// sqlPlaceholders returns n placeholders, ? or $1 to $n when numbered.
+func sqlPlaceholders(n int, numbered bool) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = "?"
+		if numbered {
+			placeholders[i] = "$" + strconv.Itoa(i+1)
+		}
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+type legislatureYear struct {
+	Legislature int
+	Year        int
+}
+
+// writeSQLite stores the deputies, their quota expenses and the party totals in the
+// SQLite database at path. The rows of each legislature and year in deputies are
+// replaced, so the database can gather several runs.
+func writeSQLite(ctx context.Context, path string, deputies []*Deputy) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range sqliteSchema {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error.sqlite.schema: %v", err)
+		}
+	}
+
+	periods := map[legislatureYear]bool{}
+	for _, d := range deputies {
+		periods[legislatureYear{d.Legislature, d.Year}] = true
+	}
+	for p := range periods {
+		for _, table := range []string{"deputies", "expenses", "parties"} {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE legislature = ? AND year = ?", p.Legislature, p.Year); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := insertSQLiteDeputies(ctx, tx, deputies); err != nil {
+		return err
+	}
+
+	if err := insertSQLiteParties(ctx, tx, deputies); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertSQLiteDeputies(ctx context.Context, tx *sql.Tx, deputies []*Deputy) error {
+	insertDeputy, err := tx.PrepareContext(ctx, "INSERT INTO deputies VALUES ("+sqlPlaceholders(len(deputyColumns), false)+")")
+	if err != nil {
+		return err
+	}
+	defer insertDeputy.Close()
+
+	insertExpense, err := tx.PrepareContext(ctx, "INSERT INTO expenses VALUES ("+sqlPlaceholders(len(expenseColumns), false)+")")
+	if err != nil {
+		return err
+	}
+	defer insertExpense.Close()
+
+	for _, d := range deputies {
+		_, err := insertDeputy.ExecContext(ctx, tableValues(deputyColumns, deputyRow(d), sqliteValue)...)
+		if err != nil {
+			return fmt.Errorf("error.sqlite.deputy.%s: %v", d.ID, err)
+		}
+
+		for _, e := range expenseRows(d) {
+			_, err := insertExpense.ExecContext(ctx, tableValues(expenseColumns, expenseRow(d, e), sqliteValue)...)
+			if err != nil {
+				return fmt.Errorf("error.sqlite.expense.%s: %v", d.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func insertSQLiteParties(ctx context.Context, tx *sql.Tx, deputies []*Deputy) error {
+	type partyKey struct {
+		legislatureYear
+		Party string
+	}
+
+	counts, totals := map[partyKey]int{}, map[partyKey]float64{}
+	for _, d := range deputies {
+		key := partyKey{legislatureYear{d.Legislature, d.Year}, d.PoliticalParty}
+		counts[key]++
+		totals[key] += d.Total
+	}
+
+	keys := make([]partyKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Year != keys[j].Year {
+			return keys[i].Year < keys[j].Year
+		}
+		return keys[i].Party < keys[j].Party
+	})
+
+	for _, key := range keys {
+		_, err := tx.ExecContext(ctx, `INSERT INTO parties VALUES (?, ?, ?, ?, ?)`, key.Legislature, key.Year, key.Party, counts[key], totals[key])
+		if err != nil {
+			return fmt.Errorf("error.sqlite.party.%s: %v", key.Party, err)
+		}
+	}
+
+	return nil
+}
+
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
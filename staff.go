@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/m2tx/gocrawler/selector"
+	"golang.org/x/net/html"
+)
+
+var (
+	// withStaff visits the cabinet staff page of every deputy to break the office budget down per employee.
+	withStaff bool
+
+	staffRowSelector selector.QueryString = "table.pessoal-gabinete tbody tr"
+)
+
+func staffURL(id string) string {
+	return fmt.Sprintf("%s/deputados/%s/pessoal-gabinete?ano=%d", baseURL, id, year)
+}
+
+// visitStaffDetails lists the cabinet employees of deputy, whose rows hold the name,
+// the role and the remuneration paid in the year, into OfficeBudgetDetails.
+func visitStaffDetails(ctx context.Context, deputy *Deputy) error {
+	if perRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, perRequestTimeout)
+		defer cancel()
+	}
+
+	deputy.OfficeBudgetDetails = nil
+
+	cell := selector.QueryString("td")
+
+	c := newCollector(ctx)
+
+	c.OnRequest(func(req *http.Request) error {
+		debugf("visit %s", req.URL)
+
+		return nil
+	})
+
+	c.OnNode(staffRowSelector, func(req *http.Request, resp *http.Response, node *html.Node) error {
+		cells := cell.Select(node)
+		if len(cells) < 3 {
+			return nil
+		}
+
+		value, err := parseReal(nodeText(cells[len(cells)-1]))
+		if errors.Is(err, errValueMissing) {
+			markMissing(deputy, "officeBudgetDetails")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error.staff: %v", err)
+		}
+
+		deputy.OfficeBudgetDetails = append(deputy.OfficeBudgetDetails, CostDetail{
+			Description: fmt.Sprintf("%s (%s)", nodeText(cells[0]), nodeText(cells[1])),
+			Value:       value,
+		})
+
+		return nil
+	})
+
+	return c.Visit(staffURL(deputy.ID))
+}
+
+// nodeText joins the text nodes under node.
+func nodeText(node *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sort"
+)
+
+type PartyStats struct {
+	Count  int     `json:"count"`
+	Total  float64 `json:"total"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+func partyStatsMap(partyMap map[string][]*Deputy) map[string]PartyStats {
+	statsMap := map[string]PartyStats{}
+	for party, deputies := range partyMap {
+		if len(deputies) == 0 {
+			continue
+		}
+
+		stats := PartyStats{Count: len(deputies)}
+
+		totals := make([]float64, 0, len(deputies))
+		for _, d := range deputies {
+			stats.Total += d.Total
+			totals = append(totals, d.Total)
+		}
+
+		stats.Mean = stats.Total / float64(stats.Count)
+		stats.Median = median(totals)
+
+		statsMap[party] = stats
+	}
+
+	return statsMap
+}
+
+func writePartyStats() error {
+	bytes, err := encodeOutputJSON(partyStatsMap(politicalPartyMap))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("political_party_stats.json", bytes)
+}
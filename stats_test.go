@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartyStatsMap(t *testing.T) {
+	stats := partyStatsMap(map[string][]*Deputy{
+		"PT": {{Total: 30}, {Total: 10}, {Total: 20}},
+		"PL": {{Total: 10}, {Total: 40}},
+	})
+
+	assert.Equal(t, PartyStats{Count: 3, Total: 60, Mean: 20, Median: 20}, stats["PT"])
+	assert.Equal(t, PartyStats{Count: 2, Total: 50, Mean: 25, Median: 25}, stats["PL"])
+}
+
+func TestDemographicStats(t *testing.T) {
+	stats := demographicStats([]*Deputy{
+		{Total: 30, Profile: &DeputyProfile{Gender: "F", Education: "Superior", BirthState: "SP"}},
+		{Total: 10, Profile: &DeputyProfile{Gender: "M", Education: "Superior", BirthState: "SP"}},
+		{Total: 20, Profile: &DeputyProfile{Gender: "F", BirthState: "RJ"}},
+		{Total: 99},
+	})
+
+	assert.Equal(t, PartyStats{Count: 2, Total: 50, Mean: 25, Median: 25}, stats["gender"]["F"])
+	assert.Equal(t, PartyStats{Count: 1, Total: 10, Mean: 10, Median: 10}, stats["gender"]["M"])
+	assert.Equal(t, PartyStats{Count: 1, Total: 20, Mean: 20, Median: 20}, stats["education"]["unknown"])
+	assert.Len(t, stats["birthState"], 2)
+}
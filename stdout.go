@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// stdoutOutput is set by -o -, which runs in a temporary directory and then writes the
+// single file of the selected -format to stdout, so godeputy can feed jq or psql. The
+// ndjson sink writes to stdout as the deputies are flushed instead.
+var stdoutOutput bool
+
+// stdoutFiles are the file written to stdout for each format -o - accepts.
+var stdoutFiles = map[string]string{
+	"json":   "deputies.json",
+	"ndjson": "deputies.ndjson",
+	"csv":    "deputies.csv",
+	"md":     "summary.md",
+}
+
+func stdoutFormats() []string {
+	var formats []string
+	for f := range stdoutFiles {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// setupStdout switches to a temporary output directory when -o is -, keeping only the
+// errors on stderr unless a lower log level was asked for with -verbose or -log-level.
+// Only scrape writes to stdout, and always uncompressed.
+func setupStdout(command string) error {
+	if outputDir != "-" {
+		return nil
+	}
+
+	if command != "scrape" {
+		return fmt.Errorf("-o - is only supported by scrape, not %s", command)
+	}
+	if compressOutput {
+		return fmt.Errorf("-o - writes to stdout uncompressed and does not accept -compress")
+	}
+
+	if outputFormats == nil {
+		outputFormats = map[string]bool{"json": true}
+	}
+	if len(outputFormats) != 1 {
+		return fmt.Errorf("-o - writes a single format to stdout, expected -format to be one of %s", strings.Join(stdoutFormats(), ", "))
+	}
+	for f := range outputFormats {
+		if _, ok := stdoutFiles[f]; !ok {
+			return fmt.Errorf("-o - cannot write %s to stdout, expected -format to be one of %s", f, strings.Join(stdoutFormats(), ", "))
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "godeputy-")
+	if err != nil {
+		return err
+	}
+
+	outputDir, stdoutOutput = dir, true
+
+	if !verbose && strings.EqualFold(logLevelName, "info") {
+		currentLogLevel = levelError
+	}
+
+	return nil
+}
+
+// closeStdout copies the file of the selected format to stdout, unless the run failed
+// before writing it or it was already streamed, and removes the temporary output directory.
+func closeStdout(runErr error) error {
+	defer os.RemoveAll(outputDir)
+
+	if runErr != nil && exitCode(runErr) != exitPartial {
+		return runErr
+	}
+
+	for f := range outputFormats {
+		if f == "ndjson" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(outputDir, outputName(stdoutFiles[f])))
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stdout.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return runErr
+}
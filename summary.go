@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// summaryTop is the number of deputies and categories listed in summary.md and report.pdf.
+const summaryTop = 10
+
+type markdownSummary struct {
+	reportSummary
+	Metadata RunMetadata
+}
+
+// encodeMarkdownSummary renders the summary as GitHub flavored markdown, short enough to
+// be pasted into an issue or a newsletter.
+func encodeMarkdownSummary(summary reportSummary, metadata RunMetadata) ([]byte, error) {
+	summary = summary.limit(summaryTop)
+
+	tmpl, err := template.New("summary").Funcs(template.FuncMap{
+		"t":     translate,
+		"money": formatMoney,
+		"cell":  markdownCell,
+		"join":  strings.Join,
+		"inc": func(i int) int {
+			return i + 1
+		},
+	}).Parse(summaryMarkdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, markdownSummary{reportSummary: summary, Metadata: metadata}); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// markdownCell keeps a value on one table cell, escaping the pipes that would split it.
+func markdownCell(v string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(v), " "), "|", `\|`)
+}
+
+func writeMarkdownSummary() error {
+	bytes, err := encodeMarkdownSummary(newReportSummary(deputiesArray), summaryMetadata())
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("summary.md", bytes)
+}
+
+const summaryMarkdownTemplate = `# {{t "report.title"}}
+
+{{t "report.legislature"}} {{.Legislature}}, {{.Year}} · {{.Deputies}} {{t "report.deputies"}} · {{t "report.total"}} {{money .Total}}
+
+## {{t "report.parties"}}
+
+| {{t "report.party"}} | {{t "report.deputies"}} | {{t "report.total"}} | {{t "report.mean"}} |
+| --- | ---: | ---: | ---: |
+{{- range .Parties}}
+| {{cell .Name}} | {{.Count}} | {{money .Total}} | {{money .Mean}} |
+{{- end}}
+
+## {{t "report.top"}}
+
+| # | {{t "report.name"}} | {{t "report.party"}} | {{t "report.state"}} | {{t "report.total"}} |
+| ---: | --- | --- | --- | ---: |
+{{- range $i, $d := .TopSpenders}}
+| {{inc $i}} | {{cell $d.Name}} | {{cell $d.PoliticalParty}} | {{cell $d.State}} | {{money $d.Total}} |
+{{- end}}
+
+## {{t "report.categories"}}
+
+| {{t "report.category"}} | {{t "report.deputies"}} | {{t "report.total"}} |
+| --- | ---: | ---: |
+{{- range .Categories}}
+| {{cell .Name}} | {{.Deputies}} | {{money .Total}} |
+{{- end}}
+
+## {{t "report.run"}}
+
+- {{t "report.started"}}: {{.Metadata.StartedAt.Format "2006-01-02 15:04 UTC"}}
+- {{t "report.finished"}}: {{.Metadata.FinishedAt.Format "2006-01-02 15:04 UTC"}}
+{{- if .Metadata.Months}}
+- {{t "report.months"}}: {{range $i, $m := .Metadata.Months}}{{if $i}}, {{end}}{{$m}}{{end}}
+{{- end}}
+- {{t "report.fields"}}: {{join .Metadata.Fields ", "}}
+`
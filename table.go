@@ -0,0 +1,139 @@
+package main
+
+// columnType is the kind of value held by a column of the table outputs. Each of
+// parquet.go, avro.go, sqlite.go, postgres.go and bigquery.go maps it to its own types.
+type columnType int
+
+const (
+	columnString columnType = iota
+	columnInt
+	columnMoney
+	columnBool
+	columnTime
+)
+
+// tableColumn is a column of the deputies and expenses tables written by the table
+// outputs. Key columns identify a deputy of a period; optional ones are null when empty.
+type tableColumn struct {
+	Name     string
+	Type     columnType
+	Key      bool
+	Optional bool
+}
+
+// deputyColumns are the columns of the deputies table, in the order of deputyRow.
+// Columns are only ever added to the end, so readers of older files keep working.
+var deputyColumns = []tableColumn{
+	{Name: "id", Type: columnString, Key: true},
+	{Name: "legislature", Type: columnInt, Key: true},
+	{Name: "year", Type: columnInt, Key: true},
+	{Name: "name", Type: columnString},
+	{Name: "political_party", Type: columnString},
+	{Name: "state", Type: columnString},
+	{Name: "salary", Type: columnMoney},
+	{Name: "office_budget", Type: columnMoney},
+	{Name: "parliamentary_quota", Type: columnMoney},
+	{Name: "air_tickets", Type: columnMoney},
+	{Name: "travel_expenses", Type: columnMoney},
+	{Name: "housing_allowance", Type: columnMoney},
+	{Name: "functional_apartment", Type: columnBool},
+	{Name: "total", Type: columnMoney},
+	{Name: "source_url", Type: columnString},
+	{Name: "scraped_at", Type: columnTime},
+}
+
+// deputyRow returns the values of d in the order of deputyColumns.
+func deputyRow(d *Deputy) []any {
+	return []any{
+		d.ID,
+		d.Legislature,
+		d.Year,
+		d.Name,
+		d.PoliticalParty,
+		d.State,
+		d.Salary,
+		d.OfficeBudget,
+		d.ParliamentaryQuota,
+		d.AirTickets,
+		d.TravelExpenses,
+		d.HousingAllowance,
+		d.FunctionalApartment,
+		d.Total,
+		d.SourceURL,
+		d.ScrapedAt,
+	}
+}
+
+// expenseKeyColumns lead the expenses table with the key of the deputy of each expense.
+// BigQuery nests the expenses in the deputy instead, with expenseDetailColumns only.
+var expenseKeyColumns = []tableColumn{
+	{Name: "deputy_id", Type: columnString, Key: true},
+	{Name: "legislature", Type: columnInt, Key: true},
+	{Name: "year", Type: columnInt, Key: true},
+}
+
+// expenseDetailColumns are the columns of an expense, in the order of expenseDetailRow.
+var expenseDetailColumns = []tableColumn{
+	{Name: "category", Type: columnString},
+	{Name: "value", Type: columnMoney},
+	{Name: "date", Type: columnString, Optional: true},
+	{Name: "supplier_name", Type: columnString, Optional: true},
+	{Name: "supplier_cnpj", Type: columnString, Optional: true},
+	{Name: "document_number", Type: columnString, Optional: true},
+	{Name: "document_url", Type: columnString, Optional: true},
+}
+
+// expenseColumns are the columns of the expenses table, in the order of expenseRow.
+var expenseColumns = append(append([]tableColumn(nil), expenseKeyColumns...), expenseDetailColumns...)
+
+func expenseDetailRow(e CostDetail) []any {
+	return []any{
+		e.Description,
+		e.Value,
+		e.Date,
+		e.SupplierName,
+		e.SupplierCNPJ,
+		e.DocumentNumber,
+		e.DocumentURL,
+	}
+}
+
+// expenseRow returns the values of the expense e of d in the order of expenseColumns.
+func expenseRow(d *Deputy, e CostDetail) []any {
+	return append([]any{d.ID, d.Legislature, d.Year}, expenseDetailRow(e)...)
+}
+
+// expenseRows lists the quota expenses of a deputy: the documents of each category when
+// they were collected with -documents, and the category totals otherwise.
+func expenseRows(d *Deputy) []CostDetail {
+	var rows []CostDetail
+	for _, detail := range d.ParliamentaryQuotaDetails {
+		if len(detail.Documents) == 0 {
+			rows = append(rows, detail)
+			continue
+		}
+		rows = append(rows, detail.Documents...)
+	}
+
+	return rows
+}
+
+// tableRecord maps the names of columns to the values of a row, each converted by
+// convert into what the format expects.
+func tableRecord(columns []tableColumn, values []any, convert func(c tableColumn, v any) any) map[string]any {
+	record := make(map[string]any, len(columns))
+	for i, c := range columns {
+		record[c.Name] = convert(c, values[i])
+	}
+
+	return record
+}
+
+// tableValues converts the values of a row in place, for the formats taking them by position.
+func tableValues(columns []tableColumn, values []any, convert func(c tableColumn, v any) any) []any {
+	for i, c := range columns {
+		values[i] = convert(c, values[i])
+	}
+
+	return values
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// plenaryOrgan is the API id of the Câmara plenary, whose roll-call votes are counted.
+const plenaryOrgan = "180"
+
+// withVotes attaches the roll-call votes of the period to every deputy.
+var withVotes bool
+
+type Votes struct {
+	RollCalls         int     `json:"rollCalls"`
+	Yes               int     `json:"yes"`
+	No                int     `json:"no"`
+	Abstention        int     `json:"abstention"`
+	Obstruction       int     `json:"obstruction"`
+	Other             int     `json:"other"`
+	Participated      int     `json:"participated"`
+	ParticipationRate float64 `json:"participationRate"`
+}
+
+type apiRollCall struct {
+	ID string `json:"id"`
+}
+
+type apiVote struct {
+	Type   string `json:"tipoVoto"`
+	Deputy struct {
+		ID int `json:"id"`
+	} `json:"deputado_"`
+}
+
+// The votes of a period are fetched once, failing or not, and shared by every worker.
+var (
+	votesMutex    sync.Mutex
+	votesLoaded   bool
+	votesPeriod   Period
+	votesByDeputy map[string]*Votes
+	votesRolls    int
+	votesErr      error
+)
+
+// attachVotes sets the roll-call votes of deputy in the period.
+func attachVotes(ctx context.Context, deputy *Deputy) error {
+	byDeputy, rollCalls, err := periodVotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	votes := Votes{RollCalls: rollCalls}
+	if v, ok := byDeputy[deputy.ID]; ok {
+		votes = *v
+	}
+	if votes.RollCalls > 0 {
+		votes.ParticipationRate = float64(votes.Participated) / float64(votes.RollCalls)
+	}
+	deputy.Votes = &votes
+
+	return nil
+}
+
+func periodVotes(ctx context.Context) (map[string]*Votes, int, error) {
+	votesMutex.Lock()
+	defer votesMutex.Unlock()
+
+	period := Period{Legislature: legislatury, Year: year}
+	if !votesLoaded || votesPeriod != period {
+		votesByDeputy, votesRolls, votesErr = loadVotes(ctx)
+		votesLoaded, votesPeriod = true, period
+	}
+
+	return votesByDeputy, votesRolls, votesErr
+}
+
+func loadVotes(ctx context.Context) (map[string]*Votes, int, error) {
+	rollCalls, err := listRollCalls(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byDeputy := map[string]*Votes{}
+	for _, id := range rollCalls {
+		err := getAPIPages(ctx, fmt.Sprintf("%s/votacoes/%s/votos", apiURL, url.PathEscape(id)), func(data json.RawMessage) error {
+			var page []apiVote
+			if err := json.Unmarshal(data, &page); err != nil {
+				return err
+			}
+
+			for _, v := range page {
+				key := fmt.Sprint(v.Deputy.ID)
+				votes, ok := byDeputy[key]
+				if !ok {
+					votes = &Votes{RollCalls: len(rollCalls)}
+					byDeputy[key] = votes
+				}
+				countVote(votes, v.Type)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return byDeputy, len(rollCalls), nil
+}
+
+func countVote(votes *Votes, kind string) {
+	votes.Participated++
+
+	switch strings.ToLower(kind) {
+	case "sim":
+		votes.Yes++
+	case "não":
+		votes.No++
+	case "abstenção":
+		votes.Abstention++
+	case "obstrução":
+		votes.Obstruction++
+	default:
+		votes.Other++
+	}
+}
+
+// listRollCalls lists the plenary roll calls of the year, or of the months of -mes,
+// asking for one month at a time since the API limits the range of each request.
+func listRollCalls(ctx context.Context) ([]string, error) {
+	selected := months
+	if len(selected) == 0 {
+		selected = allMonths()
+	}
+
+	var ids []string
+	for _, month := range selected {
+		first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		if first.After(time.Now()) {
+			break
+		}
+		last := first.AddDate(0, 1, -1)
+
+		query := url.Values{}
+		query.Set("idOrgao", plenaryOrgan)
+		query.Set("dataInicio", first.Format("2006-01-02"))
+		query.Set("dataFim", last.Format("2006-01-02"))
+		query.Set("itens", "200")
+
+		err := getAPIPages(ctx, apiURL+"/votacoes?"+query.Encode(), func(data json.RawMessage) error {
+			var page []apiRollCall
+			if err := json.Unmarshal(data, &page); err != nil {
+				return err
+			}
+
+			for _, r := range page {
+				ids = append(ids, r.ID)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// currencyFormat shows the monetary columns of the workbook as Brazilian reais.
+const currencyFormat = `"R$" #,##0.00`
+
+// xlsxSheet is a sheet of the workbook, with the columns holding monetary values.
+type xlsxSheet struct {
+	Name     string
+	Header   []string
+	Rows     [][]any
+	Currency []string
+}
+
+func deputyXLSXRow(d *Deputy) []any {
+	return []any{
+		d.ID,
+		d.Name,
+		d.PoliticalParty,
+		d.State,
+		d.Salary,
+		d.OfficeBudget,
+		d.ParliamentaryQuota,
+		d.AirTickets,
+		d.TravelExpenses,
+		d.HousingAllowance,
+		d.FunctionalApartment,
+		d.Total,
+		d.SourceURL,
+	}
+}
+
+// xlsxSheets lays the deputies, the party totals and the quota details out as the
+// Deputies, Party Totals and Cost Details sheets.
+func xlsxSheets(deputies []*Deputy, partyMap map[string][]*Deputy) []xlsxSheet {
+	sheetDeputies := xlsxSheet{Name: "Deputies", Header: deputyCSVHeader, Currency: []string{"E:J", "L:L"}}
+	for _, d := range deputies {
+		sheetDeputies.Rows = append(sheetDeputies.Rows, deputyXLSXRow(d))
+	}
+
+	stats := partyStatsMap(partyMap)
+	parties := make([]string, 0, len(stats))
+	for party := range stats {
+		parties = append(parties, party)
+	}
+	sort.Slice(parties, func(i, j int) bool {
+		return stats[parties[i]].Total > stats[parties[j]].Total
+	})
+
+	sheetParties := xlsxSheet{Name: "Party Totals", Header: []string{"party", "deputies", "total", "mean", "median"}, Currency: []string{"C:E"}}
+	for _, party := range parties {
+		s := stats[party]
+		sheetParties.Rows = append(sheetParties.Rows, []any{party, s.Count, s.Total, s.Mean, s.Median})
+	}
+
+	sheetDetails := xlsxSheet{Name: "Cost Details", Header: costDetailCSVHeader, Currency: []string{"F:F"}}
+	for _, d := range deputies {
+		for _, detail := range d.ParliamentaryQuotaDetails {
+			sheetDetails.Rows = append(sheetDetails.Rows, []any{d.ID, d.Name, d.PoliticalParty, d.State, detail.Description, detail.Value})
+		}
+	}
+
+	return []xlsxSheet{sheetDeputies, sheetParties, sheetDetails}
+}
+
+// encodeXLSX writes the sheets into a workbook with a bold, frozen header row.
+func encodeXLSX(sheets []xlsxSheet) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	format := currencyFormat
+	currency, err := f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, sheet := range sheets {
+		if i == 0 {
+			err = f.SetSheetName("Sheet1", sheet.Name)
+		} else {
+			_, err = f.NewSheet(sheet.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, columns := range sheet.Currency {
+			if err := f.SetColStyle(sheet.Name, columns, currency); err != nil {
+				return nil, err
+			}
+		}
+
+		row := make([]any, len(sheet.Header))
+		for j, name := range sheet.Header {
+			row[j] = name
+		}
+		if err := f.SetSheetRow(sheet.Name, "A1", &row); err != nil {
+			return nil, err
+		}
+		if err := f.SetRowStyle(sheet.Name, 1, 1, header); err != nil {
+			return nil, err
+		}
+
+		for j, values := range sheet.Rows {
+			cell, err := excelize.CoordinatesToCellName(1, j+2)
+			if err != nil {
+				return nil, err
+			}
+			if err := f.SetSheetRow(sheet.Name, cell, &values); err != nil {
+				return nil, err
+			}
+		}
+
+		err = f.SetPanes(sheet.Name, &excelize.Panes{
+			Freeze:      true,
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeXLSX() error {
+	bytes, err := encodeXLSX(xlsxSheets(deputiesArray, politicalPartyMap))
+	if err != nil {
+		return err
+	}
+
+	return writeOutputFile("deputies.xlsx", bytes)
+}